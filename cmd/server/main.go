@@ -1,15 +1,25 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/sainudheenp/goecom/config"
+	"github.com/sainudheenp/goecom/internal/version"
 	"github.com/sainudheenp/goecom/server"
 )
 
+// Build information, injected with -ldflags "-X main.buildVersion=... -X main.buildCommit=... -X main.buildTime=..."
+var (
+	buildVersion string
+	buildCommit  string
+	buildTime    string
+)
+
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -17,10 +27,11 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	log.Printf("Starting e-commerce server in %s mode", cfg.Server.Env)
+	buildInfo := version.New(buildVersion, buildCommit, buildTime)
+	log.Printf("Starting e-commerce server %s (%s) in %s mode", buildInfo.Version, buildInfo.Commit, cfg.Server.Env)
 
 	// Create server
-	srv, err := server.NewServer(cfg)
+	srv, err := server.NewServer(cfg, buildInfo)
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
 	}
@@ -33,8 +44,11 @@ func main() {
 		<-sigint
 
 		log.Println("Shutting down server...")
-		srv.Close()
-		os.Exit(0)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Server shutdown failed: %v", err)
+		}
 	}()
 
 	// Run server
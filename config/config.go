@@ -11,35 +11,56 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	JWT       JWTConfig
-	Security  SecurityConfig
-	CORS      CORSConfig
-	RateLimit RateLimitConfig
-	Log       LogConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	Security    SecurityConfig
+	CORS        CORSConfig
+	RateLimit   RateLimitConfig
+	Cart        CartConfig
+	Maintenance MaintenanceConfig
+	Catalog     CatalogConfig
+	Pagination  PaginationConfig
+	Log         LogConfig
+	Webhook     WebhookConfig
+	Tracing     TracingConfig
+	Health      HealthConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	Port string
-	Env  string
+	Port           string
+	Env            string
+	TLSCertFile    string
+	TLSKeyFile     string
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds database connection configuration
 type DatabaseConfig struct {
-	URL string
+	URL           string
+	ReadURL       string
+	TxMaxAttempts int
 }
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret       string
-	ExpiresHours int
+	Algorithm         string
+	Secret            string
+	PrivateKeyFile    string
+	ExpiresHours      int
+	AdminExpiresHours int
 }
 
 // SecurityConfig holds security-related configuration
 type SecurityConfig struct {
-	BcryptCost int
+	BcryptCost            int
+	SecureHeaders         bool
+	HSTSMaxAgeSeconds     int
+	ContentSecurityPolicy string
+	ForceHTTPS            bool
+	InviteTTLHours        int
+	RolePermissions       string // e.g. "admin=*;support=orders:read"
 }
 
 // CORSConfig holds CORS configuration
@@ -49,13 +70,77 @@ type CORSConfig struct {
 
 // RateLimitConfig holds rate limiting configuration
 type RateLimitConfig struct {
-	Requests      int
-	WindowMinutes int
+	Requests          int
+	ReadRequests      int
+	WriteRequests     int
+	WindowMinutes     int
+	ExemptPaths       []string
+	AuthRequests      int
+	AuthWindowMinutes int
+}
+
+// CartConfig holds shopping cart limits
+type CartConfig struct {
+	MaxItemQuantity        int
+	MaxItems               int
+	TTLHours               int
+	CleanupIntervalMinutes int
+	AddDedupWindowMS       int
+	HoldMinutes            int
+}
+
+// CatalogConfig holds product catalog configuration
+type CatalogConfig struct {
+	Currencies        []string
+	DefaultCurrency   string
+	LowStockThreshold int
+	HideOutOfStock    bool
+	AllowFreeProducts bool
+	MaxPriceCents     int
+	MaxProductImages  int
+	SearchMinLength   int
+	DefaultSort       string // sort of product lists without a sort param
+	CacheMaxAge       int    // seconds CDNs may cache public product responses
+}
+
+// PaginationConfig holds the default page sizes of list endpoints
+type PaginationConfig struct {
+	ProductsDefaultSize int
+	OrdersDefaultSize   int
+}
+
+// MaintenanceConfig holds maintenance mode configuration
+type MaintenanceConfig struct {
+	Enabled           bool
+	RetryAfterSeconds int
 }
 
 // LogConfig holds logging configuration
 type LogConfig struct {
-	Level string
+	Level         string
+	SlowRequestMS int
+	SlowQueryMS   int
+	RequestBodies bool
+}
+
+// WebhookConfig holds outbound webhook configuration
+type WebhookConfig struct {
+	URLs              []string
+	Secret            string
+	MaxAttempts       int
+	TimeoutSeconds    int
+	OutboxPollSeconds int
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	OTLPEndpoint string
+	ServiceName  string
+}
+
+// HealthConfig holds health check configuration
+type HealthConfig struct {
+	CacheSeconds int
 }
 
 // Load loads configuration from environment variables
@@ -65,29 +150,105 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:           getEnv("PORT", "8080"),
+			Env:            getEnv("ENV", "development"),
+			TLSCertFile:    getEnv("TLS_CERT_FILE", ""),
+			TLSKeyFile:     getEnv("TLS_KEY_FILE", ""),
+			TrustedProxies: getEnvSlice("TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
-			URL: getEnv("DATABASE_URL", ""),
+			URL:           getEnv("DATABASE_URL", ""),
+			ReadURL:       getEnv("DATABASE_READ_URL", ""),
+			TxMaxAttempts: getEnvInt("DATABASE_TX_MAX_ATTEMPTS", 3),
 		},
 		JWT: JWTConfig{
-			Secret:       getEnv("JWT_SECRET", ""),
-			ExpiresHours: getEnvInt("JWT_EXPIRES_HOURS", 24),
+			Algorithm:         strings.ToUpper(getEnv("JWT_ALGORITHM", "HS256")),
+			Secret:            getEnv("JWT_SECRET", ""),
+			PrivateKeyFile:    getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			ExpiresHours:      getEnvInt("JWT_EXPIRES_HOURS", 24),
+			AdminExpiresHours: getEnvInt("JWT_ADMIN_EXPIRES_HOURS", 0),
 		},
 		Security: SecurityConfig{
-			BcryptCost: getEnvInt("BCRYPT_COST", 10),
+			BcryptCost:            getEnvInt("BCRYPT_COST", 10),
+			HSTSMaxAgeSeconds:     getEnvInt("HSTS_MAX_AGE_SECONDS", 31536000),
+			ContentSecurityPolicy: getEnv("CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"),
+			ForceHTTPS:            getEnvBool("FORCE_HTTPS", false),
+			InviteTTLHours:        getEnvInt("INVITE_TTL_HOURS", 72),
+			RolePermissions:       getEnv("ROLE_PERMISSIONS", "admin=*"),
 		},
 		CORS: CORSConfig{
 			Origins: getEnvSlice("CORS_ORIGINS", []string{"*"}),
 		},
 		RateLimit: RateLimitConfig{
-			Requests:      getEnvInt("RATE_LIMIT_REQUESTS", 100),
-			WindowMinutes: getEnvInt("RATE_LIMIT_WINDOW_MINUTES", 15),
+			Requests:          getEnvInt("RATE_LIMIT_REQUESTS", 100),
+			WindowMinutes:     getEnvInt("RATE_LIMIT_WINDOW_MINUTES", 15),
+			ExemptPaths:       getEnvSlice("RATE_LIMIT_EXEMPT_PATHS", []string{"/health*", "/metrics"}),
+			AuthRequests:      getEnvInt("AUTH_RATE_LIMIT_REQUESTS", 10),
+			AuthWindowMinutes: getEnvInt("AUTH_RATE_LIMIT_WINDOW_MINUTES", 15),
+		},
+		Cart: CartConfig{
+			MaxItemQuantity:        getEnvInt("MAX_ITEM_QUANTITY", 99),
+			MaxItems:               getEnvInt("MAX_CART_ITEMS", 50),
+			TTLHours:               getEnvInt("CART_TTL_HOURS", 720),
+			CleanupIntervalMinutes: getEnvInt("CART_CLEANUP_INTERVAL_MINUTES", 60),
+			AddDedupWindowMS:       getEnvInt("CART_ADD_DEDUP_WINDOW_MS", 2000),
+			HoldMinutes:            getEnvInt("CHECKOUT_HOLD_MINUTES", 5),
+		},
+		Catalog: CatalogConfig{
+			Currencies:        getEnvSlice("ALLOWED_CURRENCIES", []string{"USD", "EUR", "GBP"}),
+			DefaultCurrency:   strings.ToUpper(getEnv("DEFAULT_CURRENCY", "USD")),
+			LowStockThreshold: getEnvInt("LOW_STOCK_THRESHOLD", 5),
+			HideOutOfStock:    getEnvBool("HIDE_OUT_OF_STOCK", false),
+			AllowFreeProducts: getEnvBool("ALLOW_FREE_PRODUCTS", false),
+			MaxPriceCents:     getEnvInt("MAX_PRICE_CENTS", 10000000),
+			MaxProductImages:  getEnvInt("MAX_PRODUCT_IMAGES", 10),
+			SearchMinLength:   getEnvInt("SEARCH_MIN_LENGTH", 2),
+			DefaultSort:       getEnv("PRODUCTS_DEFAULT_SORT", "created_desc"),
+			CacheMaxAge:       getEnvInt("PRODUCT_CACHE_MAX_AGE", 60),
+		},
+		Pagination: PaginationConfig{
+			ProductsDefaultSize: getEnvInt("PRODUCTS_DEFAULT_PAGE_SIZE", 20),
+			OrdersDefaultSize:   getEnvInt("ORDERS_DEFAULT_PAGE_SIZE", 20),
+		},
+		Maintenance: MaintenanceConfig{
+			Enabled:           getEnvBool("MAINTENANCE_MODE", false),
+			RetryAfterSeconds: getEnvInt("MAINTENANCE_RETRY_AFTER_SECONDS", 120),
 		},
 		Log: LogConfig{
-			Level: getEnv("LOG_LEVEL", "info"),
+			Level:         getEnv("LOG_LEVEL", "info"),
+			SlowRequestMS: getEnvInt("SLOW_REQUEST_MS", 1000),
+			SlowQueryMS:   getEnvInt("SLOW_QUERY_MS", 200),
+			RequestBodies: getEnvBool("LOG_REQUEST_BODIES", false),
+		},
+		Webhook: WebhookConfig{
+			URLs:              getEnvSlice("WEBHOOK_URLS", nil),
+			Secret:            getEnv("WEBHOOK_SECRET", ""),
+			MaxAttempts:       getEnvInt("WEBHOOK_MAX_ATTEMPTS", 3),
+			TimeoutSeconds:    getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+			OutboxPollSeconds: getEnvInt("WEBHOOK_OUTBOX_POLL_SECONDS", 5),
 		},
+		Tracing: TracingConfig{
+			OTLPEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			ServiceName:  getEnv("OTEL_SERVICE_NAME", "goecom"),
+		},
+		Health: HealthConfig{
+			CacheSeconds: getEnvInt("HEALTH_CACHE_SECONDS", 5),
+		},
+	}
+
+	// Security headers are on by default in production
+	cfg.Security.SecureHeaders = getEnvBool("SECURE_HEADERS", cfg.IsProduction())
+	if strings.EqualFold(cfg.Security.ContentSecurityPolicy, "off") {
+		cfg.Security.ContentSecurityPolicy = ""
+	}
+
+	// Reads default to the overall rate limit and writes to half the read budget
+	cfg.RateLimit.ReadRequests = getEnvInt("RATE_LIMIT_READ_REQUESTS", cfg.RateLimit.Requests)
+	cfg.RateLimit.WriteRequests = getEnvInt("RATE_LIMIT_WRITE_REQUESTS", max(cfg.RateLimit.ReadRequests/2, 1))
+
+	// Admin sessions default to the regular lifetime, capped at 4 hours
+	if cfg.JWT.AdminExpiresHours <= 0 {
+		cfg.JWT.AdminExpiresHours = min(cfg.JWT.ExpiresHours, 4)
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -102,15 +263,102 @@ func (c *Config) Validate() error {
 	if c.Database.URL == "" {
 		return fmt.Errorf("DATABASE_URL is required")
 	}
-	if c.JWT.Secret == "" {
-		return fmt.Errorf("JWT_SECRET is required")
+	switch c.JWT.Algorithm {
+	case "HS256":
+		if c.JWT.Secret == "" {
+			return fmt.Errorf("JWT_SECRET is required")
+		}
+		if len(c.JWT.Secret) < 32 {
+			return fmt.Errorf("JWT_SECRET must be at least 32 characters")
+		}
+	case "RS256":
+		if c.JWT.PrivateKeyFile == "" {
+			return fmt.Errorf("JWT_PRIVATE_KEY_FILE is required when JWT_ALGORITHM is RS256")
+		}
+	default:
+		return fmt.Errorf("JWT_ALGORITHM must be HS256 or RS256")
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLSEnabled() {
+		for _, file := range []string{c.Server.TLSCertFile, c.Server.TLSKeyFile} {
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("TLS file %s is not readable: %w", file, err)
+			}
+		}
+	}
+	if c.Security.InviteTTLHours < 1 {
+		return fmt.Errorf("INVITE_TTL_HOURS must be positive")
 	}
-	if len(c.JWT.Secret) < 32 {
-		return fmt.Errorf("JWT_SECRET must be at least 32 characters")
+	if c.RateLimit.ReadRequests < 1 || c.RateLimit.WriteRequests < 1 {
+		return fmt.Errorf("RATE_LIMIT_READ_REQUESTS and RATE_LIMIT_WRITE_REQUESTS must be positive")
+	}
+	if c.RateLimit.AuthRequests < 1 || c.RateLimit.AuthWindowMinutes < 1 {
+		return fmt.Errorf("AUTH_RATE_LIMIT_REQUESTS and AUTH_RATE_LIMIT_WINDOW_MINUTES must be positive")
+	}
+	if c.Cart.MaxItemQuantity < 1 || c.Cart.MaxItems < 1 {
+		return fmt.Errorf("MAX_ITEM_QUANTITY and MAX_CART_ITEMS must be positive")
+	}
+	if c.Cart.TTLHours > 0 && c.Cart.CleanupIntervalMinutes < 1 {
+		return fmt.Errorf("CART_CLEANUP_INTERVAL_MINUTES must be positive when CART_TTL_HOURS is set")
+	}
+	if c.Cart.AddDedupWindowMS < 0 {
+		return fmt.Errorf("CART_ADD_DEDUP_WINDOW_MS must not be negative")
+	}
+	if c.Cart.HoldMinutes < 0 {
+		return fmt.Errorf("CHECKOUT_HOLD_MINUTES must not be negative")
+	}
+	if c.Pagination.ProductsDefaultSize < 1 || c.Pagination.ProductsDefaultSize > 100 ||
+		c.Pagination.OrdersDefaultSize < 1 || c.Pagination.OrdersDefaultSize > 100 {
+		return fmt.Errorf("PRODUCTS_DEFAULT_PAGE_SIZE and ORDERS_DEFAULT_PAGE_SIZE must be between 1 and 100")
+	}
+	if c.Catalog.MaxPriceCents < 1 {
+		return fmt.Errorf("MAX_PRICE_CENTS must be positive")
+	}
+	if c.Catalog.MaxProductImages < 1 {
+		return fmt.Errorf("MAX_PRODUCT_IMAGES must be positive")
+	}
+	if c.Catalog.SearchMinLength < 1 {
+		return fmt.Errorf("SEARCH_MIN_LENGTH must be positive")
+	}
+	switch c.Catalog.DefaultSort {
+	case "price_asc", "price_desc", "name_asc", "name_desc", "created_desc", "featured":
+	default:
+		return fmt.Errorf("PRODUCTS_DEFAULT_SORT must be one of price_asc, price_desc, name_asc, name_desc, created_desc, featured")
+	}
+	if c.Catalog.CacheMaxAge < 0 {
+		return fmt.Errorf("PRODUCT_CACHE_MAX_AGE must not be negative")
+	}
+	if len(c.Catalog.Currencies) == 0 {
+		return fmt.Errorf("ALLOWED_CURRENCIES must list at least one currency")
+	}
+	defaultAllowed := false
+	for _, currency := range c.Catalog.Currencies {
+		if len(strings.TrimSpace(currency)) != 3 {
+			return fmt.Errorf("ALLOWED_CURRENCIES contains invalid code %q", currency)
+		}
+		if strings.EqualFold(strings.TrimSpace(currency), c.Catalog.DefaultCurrency) {
+			defaultAllowed = true
+		}
+	}
+	if !defaultAllowed {
+		return fmt.Errorf("DEFAULT_CURRENCY %q must be one of ALLOWED_CURRENCIES", c.Catalog.DefaultCurrency)
+	}
+	if len(c.Webhook.URLs) > 0 && c.Webhook.Secret == "" {
+		return fmt.Errorf("WEBHOOK_SECRET is required when WEBHOOK_URLS is set")
+	}
+	if len(c.Webhook.URLs) > 0 && c.Webhook.OutboxPollSeconds < 1 {
+		return fmt.Errorf("WEBHOOK_OUTBOX_POLL_SECONDS must be positive when WEBHOOK_URLS is set")
 	}
 	return nil
 }
 
+// TLSEnabled returns true if the server should serve HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.Server.TLSCertFile != "" && c.Server.TLSKeyFile != ""
+}
+
 // IsDevelopment returns true if the environment is development
 func (c *Config) IsDevelopment() bool {
 	return c.Server.Env == "development"
@@ -143,6 +391,19 @@ func getEnvInt(key string, defaultValue int) int {
 	return value
 }
 
+// getEnvBool gets a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // getEnvSlice gets a comma-separated environment variable as a slice
 func getEnvSlice(key string, defaultValue []string) []string {
 	valueStr := os.Getenv(key)
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.WriteFile(file, []byte("test"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		certFile    string
+		keyFile     string
+		wantTLS     bool
+		wantLoadErr bool
+	}{
+		{"no certificate", "", "", false, false},
+		{"certificate and key", certFile, keyFile, true, false},
+		{"certificate only", certFile, "", false, true},
+		{"key only", "", keyFile, false, true},
+		{"missing file", certFile, filepath.Join(dir, "missing.pem"), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://localhost/test")
+			t.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters")
+			t.Setenv("TLS_CERT_FILE", tt.certFile)
+			t.Setenv("TLS_KEY_FILE", tt.keyFile)
+
+			cfg, err := Load()
+			if tt.wantLoadErr {
+				if err == nil {
+					t.Fatal("Load() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := cfg.TLSEnabled(); got != tt.wantTLS {
+				t.Errorf("TLSEnabled() = %v, want %v", got, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestLoadDefaultPageSizes(t *testing.T) {
+	tests := []struct {
+		name         string
+		products     string
+		orders       string
+		wantProducts int
+		wantOrders   int
+		wantErr      bool
+	}{
+		{"unset", "", "", 20, 20, false},
+		{"configured", "50", "10", 50, 10, false},
+		{"at the cap", "100", "1", 100, 1, false},
+		{"products above the cap", "101", "", 0, 0, true},
+		{"orders zero", "", "0", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://localhost/test")
+			t.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters")
+			t.Setenv("PRODUCTS_DEFAULT_PAGE_SIZE", tt.products)
+			t.Setenv("ORDERS_DEFAULT_PAGE_SIZE", tt.orders)
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Pagination.ProductsDefaultSize != tt.wantProducts || cfg.Pagination.OrdersDefaultSize != tt.wantOrders {
+				t.Errorf("default page sizes = %d, %d; want %d, %d",
+					cfg.Pagination.ProductsDefaultSize, cfg.Pagination.OrdersDefaultSize, tt.wantProducts, tt.wantOrders)
+			}
+		})
+	}
+}
+
+func TestLoadDefaultProductSort(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    string
+		wantErr bool
+	}{
+		{"", "created_desc", false},
+		{"featured", "featured", false},
+		{"price_asc", "price_asc", false},
+		{"rating_desc", "", true},
+		{"FEATURED", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://localhost/test")
+			t.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters")
+			t.Setenv("PRODUCTS_DEFAULT_SORT", tt.sort)
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Catalog.DefaultSort != tt.want {
+				t.Errorf("default sort = %q, want %q", cfg.Catalog.DefaultSort, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRateLimitBudgets(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantRead  int
+		wantWrite int
+		wantErr   bool
+	}{
+		{"unset", nil, 100, 50, false},
+		{"overall limit", map[string]string{"RATE_LIMIT_REQUESTS": "40"}, 40, 20, false},
+		{"read budget", map[string]string{"RATE_LIMIT_REQUESTS": "40", "RATE_LIMIT_READ_REQUESTS": "10"}, 10, 5, false},
+		{"write budget", map[string]string{"RATE_LIMIT_WRITE_REQUESTS": "100"}, 100, 100, false},
+		{"single read keeps a write", map[string]string{"RATE_LIMIT_READ_REQUESTS": "1"}, 1, 1, false},
+		{"zero writes", map[string]string{"RATE_LIMIT_WRITE_REQUESTS": "0"}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://localhost/test")
+			t.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters")
+			for _, key := range []string{"RATE_LIMIT_REQUESTS", "RATE_LIMIT_READ_REQUESTS", "RATE_LIMIT_WRITE_REQUESTS"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Load() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.RateLimit.ReadRequests != tt.wantRead || cfg.RateLimit.WriteRequests != tt.wantWrite {
+				t.Errorf("read, write budgets = %d, %d; want %d, %d",
+					cfg.RateLimit.ReadRequests, cfg.RateLimit.WriteRequests, tt.wantRead, tt.wantWrite)
+			}
+		})
+	}
+}
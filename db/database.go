@@ -2,35 +2,84 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/sainudheenp/goecom/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// DB is the database connection pool
+// Postgres error codes for transient transaction failures
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// DB is the database connection pool.
+// Reads that can tolerate replication lag may go to a read replica through Reader.
 type DB struct {
 	*gorm.DB
+	reader        *gorm.DB
+	txMaxAttempts int
 }
 
-// NewDB creates a new database connection
-func NewDB(databaseURL string, logLevel logger.LogLevel) (*DB, error) {
+// NewDB creates a new database connection.
+// When readURL is set, a second pool is opened to that read replica for Reader.
+// Queries slower than slowQueryThreshold are logged as warnings, and transactions
+// run through WithTransaction are attempted up to txMaxAttempts times.
+func NewDB(databaseURL, readURL string, logLevel logger.LogLevel, slowQueryThreshold time.Duration, txMaxAttempts int) (*DB, error) {
+	gormLogger := logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold:             slowQueryThreshold,
+		LogLevel:                  logLevel,
+		IgnoreRecordNotFoundError: false,
+		Colorful:                  true,
+	})
+
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+		Logger: gormLogger,
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
 	}
 
+	db, err := open(databaseURL, gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Database connection established")
+
+	reader := db
+	if readURL != "" {
+		if reader, err = open(readURL, gormConfig); err != nil {
+			return nil, fmt.Errorf("read replica: %w", err)
+		}
+		log.Println("Read replica connection established")
+	}
+
+	if txMaxAttempts < 1 {
+		txMaxAttempts = 1
+	}
+
+	return &DB{DB: db, reader: reader, txMaxAttempts: txMaxAttempts}, nil
+}
+
+// open connects to a database and sets up tracing and the connection pool
+func open(databaseURL string, gormConfig *gorm.Config) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(databaseURL), gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if err := registerTracing(db); err != nil {
+		return nil, fmt.Errorf("failed to register tracing callbacks: %w", err)
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
@@ -41,13 +90,33 @@ func NewDB(databaseURL string, logLevel logger.LogLevel) (*DB, error) {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	log.Println("Database connection established")
+	return db, nil
+}
+
+// Reader returns the connection for reads that can tolerate replication lag.
+// It is the read replica when one is configured and the primary otherwise.
+func (db *DB) Reader() *gorm.DB {
+	if db.reader == nil {
+		return db.DB
+	}
+	return db.reader
+}
 
-	return &DB{db}, nil
+// Writer returns the primary connection, for writes and reads that must see them
+func (db *DB) Writer() *gorm.DB {
+	return db.DB
 }
 
-// Close closes the database connection
+// Close closes the database connections
 func (db *DB) Close() error {
+	if db.reader != nil && db.reader != db.DB {
+		if sqlDB, err := db.reader.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				log.Printf("Failed to close read replica connection: %v", err)
+			}
+		}
+	}
+
 	sqlDB, err := db.DB.DB()
 	if err != nil {
 		return err
@@ -57,12 +126,35 @@ func (db *DB) Close() error {
 
 // AutoMigrate runs automatic migrations for all models
 func (db *DB) AutoMigrate() error {
+	// Order numbers are drawn from a sequence, which AutoMigrate doesn't create
+	if err := db.DB.Exec("CREATE SEQUENCE IF NOT EXISTS order_number_seq").Error; err != nil {
+		return err
+	}
+
 	return db.DB.AutoMigrate(
 		&models.User{},
+		&models.Address{},
 		&models.Product{},
+		&models.BundleItem{},
+		&models.PriceTier{},
+		&models.FlashSale{},
+		&models.FlashSaleProduct{},
 		&models.CartItem{},
+		&models.StockHold{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.OrderNote{},
+		&models.Refund{},
+		&models.RefundItem{},
+		&models.OrderAdjustment{},
+		&models.OrderDiscount{},
+		&models.StockMovement{},
+		&models.Review{},
+		&models.ReviewVote{},
+		&models.WebhookDelivery{},
+		&models.OutboxEvent{},
+		&models.BackInStockSubscription{},
+		&models.PasswordSetupToken{},
 	)
 }
 
@@ -75,9 +167,34 @@ func (db *DB) Ping(ctx context.Context) error {
 	return sqlDB.PingContext(ctx)
 }
 
-// WithTransaction executes a function within a database transaction
+// WithTransaction executes a function within a database transaction.
+// When the transaction fails with a deadlock or serialization failure the whole
+// function is retried with a short backoff, so fn must not keep state between calls.
 func (db *DB) WithTransaction(ctx context.Context, fn func(*gorm.DB) error) error {
-	return db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return fn(tx)
-	})
+	var err error
+	for attempt := 1; attempt <= db.txMaxAttempts; attempt++ {
+		err = db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(tx)
+		})
+		if err == nil || !IsRetryableError(err) || attempt == db.txMaxAttempts {
+			return err
+		}
+
+		log.Printf("Retrying transaction after transient error (attempt %d/%d): %v", attempt, db.txMaxAttempts, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 50 * time.Millisecond):
+		}
+	}
+	return err
+}
+
+// IsRetryableError reports whether err is a deadlock or serialization failure
+func IsRetryableError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgDeadlockDetected || pgErr.Code == pgSerializationFailure
 }
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// AddressHandler handles saved address endpoints
+type AddressHandler struct {
+	db *store.DB
+}
+
+// NewAddressHandler creates a new address handler
+func NewAddressHandler(db *store.DB) *AddressHandler {
+	return &AddressHandler{
+		db: db,
+	}
+}
+
+// AddressRequest represents address create/update input
+type AddressRequest struct {
+	Label     string `json:"label"`
+	Line1     string `json:"line1" binding:"required"`
+	Line2     string `json:"line2"`
+	City      string `json:"city" binding:"required"`
+	State     string `json:"state"`
+	Country   string `json:"country" binding:"required"`
+	Postcode  string `json:"postcode" binding:"required"`
+	IsDefault bool   `json:"is_default"`
+}
+
+// ListAddresses lists the current user's saved addresses
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	var addresses []models.Address
+	if err := h.db.WithContext(c.Request.Context()).Where("user_id = ?", userID).Order("is_default DESC, created_at ASC").Find(&addresses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list addresses",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"addresses": addresses,
+	})
+}
+
+// CreateAddress saves a new address for the current user
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var address *models.Address
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		address = &models.Address{UserID: userID}
+		applyAddressRequest(address, &req)
+
+		// The first saved address becomes the default
+		var count int64
+		if err := tx.Model(&models.Address{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(address).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to create address",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// UpdateAddress replaces one of the current user's saved addresses
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid address ID",
+		})
+		return
+	}
+
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var address models.Address
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).First(&address, id).Error; err != nil {
+			return err
+		}
+
+		wasDefault := address.IsDefault
+		applyAddressRequest(&address, &req)
+		// The default can only be moved by marking another address as default
+		address.IsDefault = wasDefault || req.IsDefault
+
+		if address.IsDefault && !wasDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Save(&address).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "address not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update address",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress removes one of the current user's saved addresses
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid address ID",
+		})
+		return
+	}
+
+	result := h.db.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", id, userID).Delete(&models.Address{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to delete address",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "address not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// applyAddressRequest copies request fields onto an address
+func applyAddressRequest(address *models.Address, req *AddressRequest) {
+	address.Label = req.Label
+	address.Line1 = req.Line1
+	address.Line2 = req.Line2
+	address.City = req.City
+	address.State = req.State
+	address.Country = req.Country
+	address.Postcode = req.Postcode
+	address.IsDefault = req.IsDefault
+}
+
+// clearDefaultAddress unsets the user's current default address
+func clearDefaultAddress(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Model(&models.Address{}).
+		Where("user_id = ? AND is_default", userID).
+		Update("is_default", false).Error
+}
@@ -1,48 +1,58 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/internal/jwtkeys"
 	"github.com/sainudheenp/goecom/middleware"
 	"github.com/sainudheenp/goecom/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	db         *gorm.DB
-	jwtSecret  string
-	jwtExpires time.Duration
-	bcryptCost int
+	db              *store.DB
+	jwtKeys         *jwtkeys.Keys
+	jwtExpires      time.Duration
+	jwtAdminExpires time.Duration
+	bcryptCost      int
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(db *gorm.DB, jwtSecret string, jwtExpiresHours, bcryptCost int) *AuthHandler {
+// NewAuthHandler creates a new auth handler.
+// Tokens for admins expire after jwtAdminExpiresHours, all others after jwtExpiresHours.
+func NewAuthHandler(db *store.DB, jwtKeys *jwtkeys.Keys, jwtExpiresHours, jwtAdminExpiresHours, bcryptCost int) *AuthHandler {
 	return &AuthHandler{
-		db:         db,
-		jwtSecret:  jwtSecret,
-		jwtExpires: time.Duration(jwtExpiresHours) * time.Hour,
-		bcryptCost: bcryptCost,
+		db:              db,
+		jwtKeys:         jwtKeys,
+		jwtExpires:      time.Duration(jwtExpiresHours) * time.Hour,
+		jwtAdminExpires: time.Duration(jwtAdminExpiresHours) * time.Hour,
+		bcryptCost:      bcryptCost,
 	}
 }
 
 // RegisterRequest represents registration input
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
+	Username string `json:"username" binding:"omitempty,min=3,max=30,alphanum"`
 	Password string `json:"password" binding:"required,min=8"`
 	FullName string `json:"full_name" binding:"required"`
 }
 
 // RegisterResponse represents registration output
 type RegisterResponse struct {
-	User  models.User `json:"user"`
-	Token string      `json:"token"`
+	User      models.User `json:"user"`
+	Token     string      `json:"token"`
+	ExpiresIn int64       `json:"expires_in"`
 }
 
 // Register handles user registration
@@ -70,9 +80,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		PasswordHash: string(hashedPassword),
 		FullName:     req.FullName,
 	}
+	if req.Username != "" {
+		// Usernames are case-insensitive, so they're stored lowercased
+		username := strings.ToLower(req.Username)
+		user.Username = &username
+	}
 
-	if err := h.db.Create(user).Error; err != nil {
-		if errors.Is(err, gorm.ErrDuplicatedKey) {
+	// Rely on the unique email and username indexes rather than a prior lookup so
+	// concurrent registrations with the same email get the same response
+	if err := h.db.WithContext(c.Request.Context()).Create(user).Error; err != nil {
+		if isUniqueViolation(err) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "user already exists",
 			})
@@ -84,7 +101,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	token, err := h.generateToken(user.ID)
+	token, expiresIn, err := h.generateToken(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to generate token",
@@ -93,23 +110,27 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	resp := RegisterResponse{
-		User:  *user,
-		Token: token,
+		User:      *user,
+		Token:     token,
+		ExpiresIn: int64(expiresIn.Seconds()),
 	}
 
 	c.JSON(http.StatusCreated, resp)
 }
 
-// LoginRequest represents login input
+// LoginRequest represents login input.
+// Identifier is an email address or a username; email is still accepted for older clients.
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Identifier string `json:"identifier"`
+	Email      string `json:"email" binding:"omitempty,email"`
+	Password   string `json:"password" binding:"required"`
 }
 
 // LoginResponse represents login output
 type LoginResponse struct {
-	User  models.User `json:"user"`
-	Token string      `json:"token"`
+	User      models.User `json:"user"`
+	Token     string      `json:"token"`
+	ExpiresIn int64       `json:"expires_in"`
 }
 
 // Login handles user login
@@ -123,8 +144,27 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	identifier := strings.TrimSpace(req.Identifier)
+	if identifier == "" {
+		identifier = req.Email
+	}
+	if identifier == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "identifier or email is required",
+		})
+		return
+	}
+
+	// Usernames can't contain @, so anything with one is an email address
+	column := "email"
+	if !strings.Contains(identifier, "@") {
+		column = "username"
+		identifier = strings.ToLower(identifier)
+	}
+
+	// Deactivated users are loaded too so they get a clear error once their password checks out
 	var user models.User
-	if err := h.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	if err := h.db.WithContext(c.Request.Context()).Unscoped().Where(column+" = ?", identifier).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "invalid credentials",
@@ -144,7 +184,16 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.generateToken(user.ID)
+	if user.DeletedAt.Valid {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "account is deactivated",
+		})
+		return
+	}
+
+	h.rehashPassword(c.Request.Context(), &user, req.Password)
+
+	token, expiresIn, err := h.generateToken(&user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to generate token",
@@ -153,8 +202,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	resp := LoginResponse{
-		User:  user,
-		Token: token,
+		User:      user,
+		Token:     token,
+		ExpiresIn: int64(expiresIn.Seconds()),
 	}
 
 	c.JSON(http.StatusOK, resp)
@@ -173,16 +223,123 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// generateToken generates a JWT token for the user
-func (h *AuthHandler) generateToken(userID uuid.UUID) (string, error) {
+// JWKS serves the public keys that verify access tokens
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.jwtKeys.JWKS())
+}
+
+// SetPasswordRequest represents password setup input
+type SetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// errInvalidSetupToken is returned for unknown, used or expired password setup tokens
+var errInvalidSetupToken = errors.New("invalid or expired token")
+
+// SetPassword sets an invited user's password using the single-use token from their invite
+func (h *AuthHandler) SetPassword(c *gin.Context) {
+	var req SetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), h.bcryptCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to hash password",
+		})
+		return
+	}
+
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		// Locking the token keeps two concurrent requests from both using it
+		var token models.PasswordSetupToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashPasswordSetupToken(req.Token), time.Now()).
+			First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errInvalidSetupToken
+			}
+			return err
+		}
+
+		result := tx.Model(&models.User{}).Where("id = ?", token.UserID).Update("password_hash", string(hash))
+		if result.Error != nil {
+			return result.Error
+		}
+		// Deactivated users can't be set up
+		if result.RowsAffected == 0 {
+			return errInvalidSetupToken
+		}
+
+		return tx.Model(&token).Update("used_at", time.Now()).Error
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidSetupToken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to set password",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// rehashPassword upgrades a password hash made with a lower cost than the configured one.
+// It runs after a successful login, the only time the plain password is known. Failures are
+// logged and leave the old hash in place, since it still verifies.
+func (h *AuthHandler) rehashPassword(ctx context.Context, user *models.User, password string) {
+	cost, err := bcrypt.Cost([]byte(user.PasswordHash))
+	if err != nil || cost >= h.bcryptCost {
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", user.ID, err)
+		return
+	}
+
+	// Matching the old hash keeps a concurrent password change from being overwritten
+	if err := h.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND password_hash = ?", user.ID, user.PasswordHash).
+		Update("password_hash", string(hash)).Error; err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = string(hash)
+}
+
+// generateToken generates a JWT token for the user and returns its lifetime,
+// which depends on the user's role
+func (h *AuthHandler) generateToken(user *models.User) (string, time.Duration, error) {
+	expires := h.jwtExpires
+	if user.Role == "admin" {
+		expires = h.jwtAdminExpires
+	}
+
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"user_id": userID.String(),
-		"exp":     time.Now().Add(h.jwtExpires).Unix(),
-		"iat":     time.Now().Unix(),
+		"user_id": user.ID.String(),
+		"exp":     now.Add(expires).Unix(),
+		"iat":     now.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(h.jwtSecret))
+	signed, err := h.jwtKeys.Sign(claims)
+	if err != nil {
+		return "", 0, err
+	}
+	return signed, expires, nil
 }
 
 // ErrorResponse represents an error response
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/internal/jwtkeys"
+	"github.com/sainudheenp/goecom/models"
+)
+
+func TestGenerateTokenExpiryByRole(t *testing.T) {
+	keys := jwtkeys.NewHMAC("test-secret-that-is-at-least-32-characters")
+	h := NewAuthHandler(nil, keys, 24, 2, 4)
+
+	tests := []struct {
+		role string
+		want time.Duration
+	}{
+		{"user", 24 * time.Hour},
+		{"admin", 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			signed, expires, err := h.generateToken(&models.User{ID: uuid.New(), Role: tt.role})
+			if err != nil {
+				t.Fatalf("generateToken() error = %v", err)
+			}
+			if expires != tt.want {
+				t.Errorf("lifetime = %v, want %v", expires, tt.want)
+			}
+
+			token, err := keys.Parse(signed)
+			if err != nil {
+				t.Fatalf("parse token: %v", err)
+			}
+			claims := token.Claims.(jwt.MapClaims)
+			exp, _ := claims.GetExpirationTime()
+			iat, _ := claims.GetIssuedAt()
+			if got := exp.Sub(iat.Time); got != tt.want {
+				t.Errorf("exp - iat = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/internal/webhook"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProductBackInStockEvent is the webhook payload sent when an out-of-stock product is restocked.
+// UserIDs lists the subscribers to notify; each subscriber appears in exactly one event.
+type ProductBackInStockEvent struct {
+	ProductID uuid.UUID   `json:"product_id"`
+	UserIDs   []uuid.UUID `json:"user_ids"`
+}
+
+// NotifyMe subscribes the current user to a back-in-stock notification for an out-of-stock product
+func (h *ProductHandler) NotifyMe(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+
+	var product models.Product
+	if err := db.Select("id", "stock").Where("status = ?", models.ProductStatusPublished).First(&product, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	if product.Stock > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "product is in stock",
+		})
+		return
+	}
+
+	subscription := &models.BackInStockSubscription{
+		ProductID: productID,
+		UserID:    userID,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(subscription)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to create subscription",
+		})
+		return
+	}
+
+	// Subscribing twice is harmless; return the pending subscription
+	if result.RowsAffected == 0 {
+		subscription = &models.BackInStockSubscription{}
+		if err := db.Where("product_id = ? AND user_id = ? AND NOT notified", productID, userID).First(subscription).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to get subscription",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, subscription)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// notifyBackInStock marks pending subscriptions for products that are in stock again as notified
+// and enqueues one event per product. Call it after a transaction that increased stock commits.
+// Marking and collecting subscribers is a single UPDATE, so concurrent calls never notify anyone twice.
+func notifyBackInStock(ctx context.Context, db *store.DB, events *webhook.Publisher) {
+	err := db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var rows []struct {
+			ProductID uuid.UUID
+			UserID    uuid.UUID
+		}
+		if err := tx.Raw(`
+			UPDATE back_in_stock_subscriptions s
+			SET notified = TRUE, updated_at = NOW()
+			FROM products p
+			WHERE p.id = s.product_id AND p.stock > 0 AND NOT s.notified
+			RETURNING s.product_id, s.user_id`).
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		var productIDs []uuid.UUID
+		subscribers := make(map[uuid.UUID][]uuid.UUID)
+		for _, row := range rows {
+			if _, ok := subscribers[row.ProductID]; !ok {
+				productIDs = append(productIDs, row.ProductID)
+			}
+			subscribers[row.ProductID] = append(subscribers[row.ProductID], row.UserID)
+		}
+
+		for _, productID := range productIDs {
+			if err := events.Enqueue(tx, webhook.EventProductBackInStock, ProductBackInStockEvent{
+				ProductID: productID,
+				UserIDs:   subscribers[productID],
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Subscriptions stay pending and are picked up after the next restock
+		log.Printf("Failed to notify back-in-stock subscribers: %v", err)
+	}
+}
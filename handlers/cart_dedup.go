@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// addToCartKey identifies an add-to-cart request for deduplication
+type addToCartKey struct {
+	userID    uuid.UUID
+	productID uuid.UUID
+	quantity  int
+}
+
+// addToCartGuard remembers recent add-to-cart requests so a double submit is only applied once.
+// It is in memory, so each server instance deduplicates on its own.
+type addToCartGuard struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	claimed   map[addToCartKey]time.Time
+	lastPrune time.Time
+}
+
+// newAddToCartGuard creates a guard; a zero window disables deduplication
+func newAddToCartGuard(window time.Duration) *addToCartGuard {
+	return &addToCartGuard{
+		window:  window,
+		claimed: make(map[addToCartKey]time.Time),
+	}
+}
+
+// claim records a request and reports whether it is new, i.e. no identical request
+// was claimed within the window
+func (g *addToCartGuard) claim(key addToCartKey) bool {
+	if g.window <= 0 {
+		return true
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(g.lastPrune) > g.window {
+		for k, claimedAt := range g.claimed {
+			if now.Sub(claimedAt) > g.window {
+				delete(g.claimed, k)
+			}
+		}
+		g.lastPrune = now
+	}
+
+	if claimedAt, ok := g.claimed[key]; ok && now.Sub(claimedAt) <= g.window {
+		return false
+	}
+	g.claimed[key] = now
+	return true
+}
+
+// release forgets a claimed request that failed, so retrying it isn't swallowed
+func (g *addToCartGuard) release(key addToCartKey) {
+	if g.window <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.claimed, key)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAddToCartGuardClaim(t *testing.T) {
+	userID, productID := uuid.New(), uuid.New()
+	key := addToCartKey{userID: userID, productID: productID, quantity: 2}
+
+	tests := []struct {
+		name  string
+		other addToCartKey
+		want  bool
+	}{
+		{"same request", key, false},
+		{"other quantity", addToCartKey{userID: userID, productID: productID, quantity: 3}, true},
+		{"other product", addToCartKey{userID: userID, productID: uuid.New(), quantity: 2}, true},
+		{"other user", addToCartKey{userID: uuid.New(), productID: productID, quantity: 2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := newAddToCartGuard(time.Minute)
+			if !guard.claim(key) {
+				t.Fatal("first claim refused")
+			}
+			if got := guard.claim(tt.other); got != tt.want {
+				t.Errorf("claim() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddToCartGuardWindowExpires(t *testing.T) {
+	guard := newAddToCartGuard(20 * time.Millisecond)
+	key := addToCartKey{userID: uuid.New(), productID: uuid.New(), quantity: 1}
+
+	if !guard.claim(key) {
+		t.Fatal("first claim refused")
+	}
+	if guard.claim(key) {
+		t.Fatal("duplicate within the window accepted")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !guard.claim(key) {
+		t.Error("claim after the window refused")
+	}
+}
+
+func TestAddToCartGuardRelease(t *testing.T) {
+	guard := newAddToCartGuard(time.Minute)
+	key := addToCartKey{userID: uuid.New(), productID: uuid.New(), quantity: 1}
+
+	guard.claim(key)
+	guard.release(key)
+	if !guard.claim(key) {
+		t.Error("claim after release refused")
+	}
+}
+
+func TestAddToCartGuardDisabled(t *testing.T) {
+	guard := newAddToCartGuard(0)
+	key := addToCartKey{userID: uuid.New(), productID: uuid.New(), quantity: 1}
+
+	for i := 0; i < 3; i++ {
+		if !guard.claim(key) {
+			t.Fatalf("claim %d refused with deduplication disabled", i+1)
+		}
+	}
+	guard.release(key)
+}
+
+func TestAddToCartGuardPrunes(t *testing.T) {
+	guard := newAddToCartGuard(10 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		guard.claim(addToCartKey{userID: uuid.New(), productID: uuid.New(), quantity: 1})
+	}
+	time.Sleep(20 * time.Millisecond)
+	guard.claim(addToCartKey{userID: uuid.New(), productID: uuid.New(), quantity: 1})
+
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	if len(guard.claimed) != 1 {
+		t.Errorf("claimed holds %d keys after pruning, want 1", len(guard.claimed))
+	}
+}
+
+func TestAddToCartGuardConcurrentDoubleSubmit(t *testing.T) {
+	guard := newAddToCartGuard(time.Minute)
+	key := addToCartKey{userID: uuid.New(), productID: uuid.New(), quantity: 1}
+
+	var accepted atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if guard.claim(key) {
+				accepted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted.Load() != 1 {
+		t.Errorf("%d concurrent claims accepted, want 1", accepted.Load())
+	}
+}
@@ -0,0 +1,488 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// CartLimits caps how much a single cart can hold.
+// MaxItemQuantity is in kilograms for products sold by weight.
+type CartLimits struct {
+	MaxItemQuantity int
+	MaxItems        int
+}
+
+// maxQuantity returns the largest quantity of a product a single cart line may hold
+func (l CartLimits) maxQuantity(product *models.Product) int {
+	if product.UnitType == models.UnitTypeWeight {
+		return l.MaxItemQuantity * models.GramsPerKilogram
+	}
+	return l.MaxItemQuantity
+}
+
+// CartHandler handles shopping cart endpoints
+type CartHandler struct {
+	db       *store.DB
+	limits   CartLimits
+	addGuard *addToCartGuard
+}
+
+// NewCartHandler creates a new cart handler.
+// Identical add-to-cart requests from a user within dedupWindow are applied once; 0 disables this.
+func NewCartHandler(db *store.DB, limits CartLimits, dedupWindow time.Duration) *CartHandler {
+	return &CartHandler{
+		db:       db,
+		limits:   limits,
+		addGuard: newAddToCartGuard(dedupWindow),
+	}
+}
+
+// CartItemResponse represents a cart line with its computed subtotal.
+// UnitPriceCents includes any volume discount or flash sale price the quantity qualifies for.
+// PriceChanged is set when the product's price differs from the price when it was added.
+type CartItemResponse struct {
+	models.CartItem
+	UnitPriceCents int   `json:"unit_price_cents"`
+	SubtotalCents  int64 `json:"subtotal_cents"`
+	PriceChanged   bool  `json:"price_changed"`
+}
+
+// CartResponse represents the user's cart
+type CartResponse struct {
+	Items      []CartItemResponse `json:"items"`
+	TotalCents int64              `json:"total_cents"`
+	Currency   string             `json:"currency"`
+}
+
+// GetCart returns the current user's cart
+func (h *CartHandler) GetCart(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	h.writeCart(c, userID)
+}
+
+// GetUserCart retrieves any user's cart for support (admin only)
+func (h *CartHandler) GetUserCart(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	// Deactivated users are included so support can still look them up
+	var count int64
+	if err := h.db.WithContext(c.Request.Context()).Unscoped().Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get user",
+		})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
+		})
+		return
+	}
+
+	h.writeCart(c, userID)
+}
+
+// writeCart loads a user's cart and writes it as the response
+func (h *CartHandler) writeCart(c *gin.Context, userID uuid.UUID) {
+	cart, err := loadCart(h.db.WithContext(c.Request.Context()), userID)
+	if err != nil {
+		if errors.Is(err, models.ErrCurrencyMismatch) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "cart contains items in multiple currencies",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get cart",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// AddToCartRequest represents add-to-cart input.
+// Quantity is in grams for products sold by weight.
+type AddToCartRequest struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,min=1"`
+}
+
+// AddToCart adds a product to the cart or updates its quantity.
+// A repeat of the same request within the dedup window is a no-op that returns the current cart.
+func (h *CartHandler) AddToCart(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	var req AddToCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key := addToCartKey{userID: userID, productID: req.ProductID, quantity: req.Quantity}
+	if !h.addGuard.claim(key) {
+		h.writeCart(c, userID)
+		return
+	}
+	// Failed requests are forgotten so the client can retry straight away
+	added := false
+	defer func() {
+		if !added {
+			h.addGuard.release(key)
+		}
+	}()
+
+	var product models.Product
+	// Drafts and archived products can't be bought, so they look missing here
+	if err := h.db.WithContext(c.Request.Context()).Where("status = ?", models.ProductStatusPublished).First(&product, req.ProductID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	if maxQuantity := h.limits.maxQuantity(&product); req.Quantity > maxQuantity {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("quantity exceeds the maximum of %d per item", maxQuantity),
+		})
+		return
+	}
+
+	// Stock held by other shoppers' checkouts isn't available
+	held, err := heldByOthers(h.db.WithContext(c.Request.Context()), []uuid.UUID{product.ID}, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+	if product.Stock-held[product.ID] < req.Quantity {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "insufficient stock",
+		})
+		return
+	}
+
+	// Reject products priced in a different currency than the rest of the cart
+	cart, err := loadCart(h.db.WithContext(c.Request.Context()), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get cart",
+		})
+		return
+	}
+	if _, err := models.NewMoney(cart.TotalCents, cart.Currency).Add(product.Price()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "product currency does not match cart currency",
+		})
+		return
+	}
+	if !cart.hasProduct(req.ProductID) && len(cart.Items) >= h.limits.MaxItems {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("cart cannot hold more than %d items", h.limits.MaxItems),
+		})
+		return
+	}
+
+	if err := setCartItemQuantity(h.db.WithContext(c.Request.Context()), userID, &product, req.Quantity); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to add item to cart",
+		})
+		return
+	}
+	added = true
+
+	cart, err = loadCart(h.db.WithContext(c.Request.Context()), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get cart",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+// CartItemInput represents a single item in a bulk add request
+type CartItemInput struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,min=1"`
+}
+
+// BulkAddToCartRequest represents bulk add-to-cart input
+type BulkAddToCartRequest struct {
+	Items []CartItemInput `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// CartItemResult reports the outcome for one item of a bulk add
+type CartItemResult struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Added     bool      `json:"added"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// BulkAddToCart adds or updates several cart items in one transaction.
+// Items that can't be added are reported individually without failing the others.
+func (h *CartHandler) BulkAddToCart(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	var req BulkAddToCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	var results []CartItemResult
+	var cart *CartResponse
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		var err error
+		if results, err = addManyToCart(tx, userID, req.Items, h.limits); err != nil {
+			return err
+		}
+		cart, err = loadCart(tx, userID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to add items to cart",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart":    cart,
+		"results": results,
+	})
+}
+
+// RemoveFromCart removes an item from the cart. Removing an item that is already gone succeeds,
+// so retries are safe. The response is empty unless return=cart is given or the request sends
+// Prefer: return=representation, in which case the updated cart is returned.
+func (h *CartHandler) RemoveFromCart(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid cart item ID",
+		})
+		return
+	}
+
+	ret := c.Query("return")
+	if ret != "" && ret != "cart" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "return must be cart",
+		})
+		return
+	}
+	returnCart := ret == "cart" || strings.Contains(c.GetHeader("Prefer"), "return=representation")
+
+	if err := h.db.WithContext(c.Request.Context()).Where("id = ? AND user_id = ?", itemID, userID).Delete(&models.CartItem{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to remove item from cart",
+		})
+		return
+	}
+
+	if returnCart {
+		h.writeCart(c, userID)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadCart loads the user's cart items and computes the totals
+func loadCart(db *gorm.DB, userID uuid.UUID) (*CartResponse, error) {
+	var items []models.CartItem
+	if err := db.Preload("Product.PriceTiers").Where("user_id = ?", userID).Order("created_at ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if err := attachCartFlashSales(db, items); err != nil {
+		return nil, err
+	}
+
+	cart := &CartResponse{
+		Items: make([]CartItemResponse, 0, len(items)),
+	}
+	var total models.Money
+	for _, item := range items {
+		if item.Product == nil {
+			continue
+		}
+		subtotal := item.Product.LineTotal(item.Quantity)
+		var err error
+		if total, err = total.Add(subtotal); err != nil {
+			return nil, err
+		}
+		cart.Items = append(cart.Items, CartItemResponse{
+			CartItem:       item,
+			UnitPriceCents: item.Product.UnitPriceCents(item.Quantity),
+			SubtotalCents:  subtotal.AmountCents,
+			// Items added before prices were recorded have no price to compare
+			PriceChanged: item.PriceCentsAtAdd != 0 && item.PriceCentsAtAdd != item.Product.PriceCents,
+		})
+	}
+	cart.TotalCents = total.AmountCents
+	cart.Currency = total.Currency
+
+	return cart, nil
+}
+
+// hasProduct reports whether the cart already contains a product
+func (cart *CartResponse) hasProduct(productID uuid.UUID) bool {
+	for _, item := range cart.Items {
+		if item.ProductID == productID {
+			return true
+		}
+	}
+	return false
+}
+
+// addManyToCart adds or updates several cart items, validating each against stock, the cart limits
+// and the cart currency. Failures for individual items are returned as results; only database errors abort the batch.
+func addManyToCart(tx *gorm.DB, userID uuid.UUID, inputs []CartItemInput, limits CartLimits) ([]CartItemResult, error) {
+	productIDs := make([]uuid.UUID, 0, len(inputs))
+	for _, input := range inputs {
+		productIDs = append(productIDs, input.ProductID)
+	}
+
+	// Drafts and archived products can't be bought, so they look missing here as in AddToCart
+	var products []models.Product
+	if err := tx.Where("id IN ? AND status = ?", productIDs, models.ProductStatusPublished).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	productsByID := make(map[uuid.UUID]*models.Product, len(products))
+	for i := range products {
+		productsByID[products[i].ID] = &products[i]
+	}
+	// Stock held by other shoppers' checkouts isn't available
+	held, err := heldByOthers(tx, productIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := loadCart(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	total := models.NewMoney(cart.TotalCents, cart.Currency)
+	inCart := make(map[uuid.UUID]bool, len(cart.Items))
+	for _, item := range cart.Items {
+		inCart[item.ProductID] = true
+	}
+
+	results := make([]CartItemResult, 0, len(inputs))
+	for _, input := range inputs {
+		result := CartItemResult{
+			ProductID: input.ProductID,
+			Quantity:  input.Quantity,
+		}
+
+		product, ok := productsByID[input.ProductID]
+		switch {
+		case !ok:
+			result.Error = "product not found"
+		case input.Quantity > limits.maxQuantity(product):
+			result.Error = fmt.Sprintf("quantity exceeds the maximum of %d per item", limits.maxQuantity(product))
+		case !inCart[input.ProductID] && len(inCart) >= limits.MaxItems:
+			result.Error = fmt.Sprintf("cart cannot hold more than %d items", limits.MaxItems)
+		case product.Stock-held[product.ID] < input.Quantity:
+			result.Error = "insufficient stock"
+		default:
+			next, err := total.Add(product.Price())
+			if err != nil {
+				result.Error = "product currency does not match cart currency"
+				break
+			}
+			if err := setCartItemQuantity(tx, userID, product, input.Quantity); err != nil {
+				return nil, err
+			}
+			total = next
+			inCart[input.ProductID] = true
+			result.Added = true
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// setCartItemQuantity creates the user's cart item for a product or updates its quantity.
+// The product's current price is recorded since the user has just seen it.
+func setCartItemQuantity(db *gorm.DB, userID uuid.UUID, product *models.Product, quantity int) error {
+	var item models.CartItem
+	err := db.Where("user_id = ? AND product_id = ?", userID, product.ID).First(&item).Error
+	switch {
+	case err == nil:
+		item.Quantity = quantity
+		item.PriceCentsAtAdd = product.PriceCents
+		return db.Save(&item).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		item = models.CartItem{
+			UserID:          userID,
+			ProductID:       product.ID,
+			Quantity:        quantity,
+			PriceCentsAtAdd: product.PriceCents,
+		}
+		return db.Create(&item).Error
+	default:
+		return err
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// Cart validation change types
+const (
+	cartChangeUnavailable  = "unavailable"
+	cartChangeOutOfStock   = "out_of_stock"
+	cartChangeInsufficient = "insufficient_stock"
+	cartChangePrice        = "price_changed"
+)
+
+// CartChange is a difference between a cart item and its product's current state.
+// Adjusted is set when the cart was changed to resolve it.
+type CartChange struct {
+	ItemID            uuid.UUID `json:"item_id"`
+	ProductID         uuid.UUID `json:"product_id"`
+	Type              string    `json:"type"`
+	Quantity          int       `json:"quantity,omitempty"`
+	AvailableQuantity *int      `json:"available_quantity,omitempty"`
+	OldPriceCents     int       `json:"old_price_cents,omitempty"`
+	NewPriceCents     int       `json:"new_price_cents,omitempty"`
+	Adjusted          bool      `json:"adjusted"`
+}
+
+// ValidateCart re-checks the current user's cart against current prices and stock before checkout.
+// Items whose product is gone or no longer published are reported as unavailable, items without
+// enough stock (net of other shoppers' holds) as out_of_stock or insufficient_stock, and items whose
+// price moved since they were added as price_changed. With adjust=true the cart is reconciled:
+// unavailable and out-of-stock items are removed, quantities are lowered to the available stock
+// and changed prices are accepted. The response has the resulting cart and the changes found.
+func (h *CartHandler) ValidateCart(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	adjust, err := strconv.ParseBool(c.DefaultQuery("adjust", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "adjust must be true or false",
+		})
+		return
+	}
+
+	var changes []CartChange
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		changes = []CartChange{}
+		var items []models.CartItem
+		if err := tx.Preload("Product").Where("user_id = ?", userID).Order("created_at ASC").Find(&items).Error; err != nil {
+			return err
+		}
+
+		productIDs := make([]uuid.UUID, 0, len(items))
+		for _, item := range items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		held, err := heldByOthers(tx, productIDs, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			change := CartChange{ItemID: item.ID, ProductID: item.ProductID, Quantity: item.Quantity}
+
+			if item.Product == nil || item.Product.Status != models.ProductStatusPublished {
+				change.Type = cartChangeUnavailable
+				if adjust {
+					if err := tx.Delete(&models.CartItem{}, item.ID).Error; err != nil {
+						return err
+					}
+					change.Adjusted = true
+				}
+				changes = append(changes, change)
+				continue
+			}
+
+			available := max(item.Product.Stock-held[item.ProductID], 0)
+			if item.Quantity > available {
+				change.AvailableQuantity = &available
+				change.Type = cartChangeInsufficient
+				if available == 0 {
+					change.Type = cartChangeOutOfStock
+				}
+				if adjust {
+					var err error
+					if available == 0 {
+						err = tx.Delete(&models.CartItem{}, item.ID).Error
+					} else {
+						err = tx.Model(&models.CartItem{}).Where("id = ?", item.ID).Update("quantity", available).Error
+					}
+					if err != nil {
+						return err
+					}
+					change.Adjusted = true
+				}
+				changes = append(changes, change)
+				if available == 0 {
+					continue
+				}
+			}
+
+			// Items added before prices were recorded have no price to compare
+			if item.PriceCentsAtAdd != 0 && item.PriceCentsAtAdd != item.Product.PriceCents {
+				priceChange := CartChange{
+					ItemID:        item.ID,
+					ProductID:     item.ProductID,
+					Type:          cartChangePrice,
+					OldPriceCents: item.PriceCentsAtAdd,
+					NewPriceCents: item.Product.PriceCents,
+				}
+				if adjust {
+					if err := tx.Model(&models.CartItem{}).Where("id = ?", item.ID).
+						Update("price_cents_at_add", item.Product.PriceCents).Error; err != nil {
+						return err
+					}
+					priceChange.Adjusted = true
+				}
+				changes = append(changes, priceChange)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to validate cart",
+		})
+		return
+	}
+
+	cart, err := loadCart(h.db.WithContext(c.Request.Context()), userID)
+	if err != nil {
+		if errors.Is(err, models.ErrCurrencyMismatch) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "cart contains items in multiple currencies",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get cart",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart":    cart,
+		"changes": changes,
+	})
+}
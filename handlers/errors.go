@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// pgUniqueViolation is the Postgres error code for unique constraint violations
+const pgUniqueViolation = "23505"
+
+// isUniqueViolation reports whether err was caused by a unique constraint violation
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// errFlashSaleLimit is returned when an order would take a user past a flash sale's per-user limit
+var errFlashSaleLimit = errors.New("flash sale per-user limit exceeded")
+
+// flashSaleStatuses maps the status filter of the flash sale list to its condition; ? is the current time
+var flashSaleStatuses = map[string]string{
+	"upcoming": "starts_at > ?",
+	"active":   "starts_at <= ? AND ends_at > ?",
+	"ended":    "ends_at <= ?",
+}
+
+// FlashSaleHandler handles flash sale endpoints
+type FlashSaleHandler struct {
+	db *store.DB
+}
+
+// NewFlashSaleHandler creates a new flash sale handler
+func NewFlashSaleHandler(db *store.DB) *FlashSaleHandler {
+	return &FlashSaleHandler{
+		db: db,
+	}
+}
+
+// CreateFlashSaleRequest represents flash sale input
+type CreateFlashSaleRequest struct {
+	Name         string      `json:"name" binding:"required,max=200"`
+	ProductIDs   []uuid.UUID `json:"product_ids" binding:"required,min=1,max=100"`
+	PercentOff   int         `json:"percent_off" binding:"required,min=1,max=99"`
+	StartsAt     time.Time   `json:"starts_at" binding:"required"`
+	EndsAt       time.Time   `json:"ends_at" binding:"required"`
+	PerUserLimit *int        `json:"per_user_limit" binding:"omitempty,min=1"`
+}
+
+// CreateFlashSale schedules a flash sale on a set of products (admin only).
+// The sale price applies from starts_at until ends_at without further action.
+func (h *FlashSaleHandler) CreateFlashSale(c *gin.Context) {
+	var req CreateFlashSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ends_at must be after starts_at",
+		})
+		return
+	}
+	if !req.EndsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ends_at must be in the future",
+		})
+		return
+	}
+
+	sale := &models.FlashSale{
+		Name:         req.Name,
+		PercentOff:   req.PercentOff,
+		StartsAt:     req.StartsAt.UTC(),
+		EndsAt:       req.EndsAt.UTC(),
+		PerUserLimit: req.PerUserLimit,
+	}
+	seen := make(map[uuid.UUID]bool, len(req.ProductIDs))
+	for _, productID := range req.ProductIDs {
+		if seen[productID] {
+			continue
+		}
+		seen[productID] = true
+		sale.Products = append(sale.Products, models.FlashSaleProduct{ProductID: productID})
+	}
+
+	err := h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		var found int64
+		if err := tx.Model(&models.Product{}).Where("id IN ?", req.ProductIDs).Count(&found).Error; err != nil {
+			return err
+		}
+		if int(found) != len(sale.Products) {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Create(sale).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to create flash sale",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sale)
+}
+
+// ListFlashSales lists flash sales, latest start first (admin only).
+// status filters to upcoming, active or ended sales.
+func (h *FlashSaleHandler) ListFlashSales(c *gin.Context) {
+	dbQuery := h.db.WithContext(c.Request.Context()).Preload("Products")
+
+	if status := c.Query("status"); status != "" {
+		condition, ok := flashSaleStatuses[status]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "status must be one of upcoming, active, ended",
+			})
+			return
+		}
+		now := time.Now().UTC()
+		if status == "active" {
+			dbQuery = dbQuery.Where(condition, now, now)
+		} else {
+			dbQuery = dbQuery.Where(condition, now)
+		}
+	}
+
+	sales := []models.FlashSale{}
+	if err := dbQuery.Order("starts_at DESC").Find(&sales).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list flash sales",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flash_sales": sales,
+	})
+}
+
+// DeleteFlashSale cancels a flash sale; a running sale ends immediately (admin only)
+func (h *FlashSaleHandler) DeleteFlashSale(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid flash sale ID",
+		})
+		return
+	}
+
+	// The product links go first; a schema built by AutoMigrate doesn't cascade the delete
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		if err := tx.Where("flash_sale_id = ?", id).Delete(&models.FlashSaleProduct{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.FlashSale{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "flash sale not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to delete flash sale",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// runningFlashSale is a running flash sale of one product
+type runningFlashSale struct {
+	ProductID    uuid.UUID
+	ID           uuid.UUID
+	PercentOff   int
+	EndsAt       time.Time
+	PerUserLimit *int
+}
+
+// attachFlashSales sets FlashSale on each product with a running flash sale.
+// A product in several running sales gets the biggest discount.
+func attachFlashSales(db *gorm.DB, products []*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	productIDs := make([]uuid.UUID, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+
+	var running []runningFlashSale
+	now := time.Now().UTC()
+	if err := db.Table("flash_sale_products").
+		Select("flash_sale_products.product_id, flash_sales.id, flash_sales.percent_off, flash_sales.ends_at, flash_sales.per_user_limit").
+		Joins("JOIN flash_sales ON flash_sales.id = flash_sale_products.flash_sale_id").
+		Where("flash_sale_products.product_id IN ? AND flash_sales.starts_at <= ? AND flash_sales.ends_at > ?", productIDs, now, now).
+		Order("flash_sales.percent_off DESC, flash_sales.ends_at ASC").
+		Scan(&running).Error; err != nil {
+		return err
+	}
+	applyFlashSales(products, running)
+	return nil
+}
+
+// applyFlashSales sets FlashSale on each product from the running sales, best first, and clears it
+// on products without one so a sale that has ended no longer prices them
+func applyFlashSales(products []*models.Product, running []runningFlashSale) {
+	sales := make(map[uuid.UUID]*models.FlashSale, len(running))
+	for _, row := range running {
+		if _, ok := sales[row.ProductID]; !ok {
+			sales[row.ProductID] = &models.FlashSale{
+				ID:           row.ID,
+				PercentOff:   row.PercentOff,
+				EndsAt:       row.EndsAt,
+				PerUserLimit: row.PerUserLimit,
+			}
+		}
+	}
+	for _, product := range products {
+		sale, ok := sales[product.ID]
+		if !ok {
+			product.FlashSale = nil
+			continue
+		}
+		product.FlashSale = &models.ProductFlashSale{
+			ID:             sale.ID,
+			PercentOff:     sale.PercentOff,
+			SalePriceCents: sale.SalePriceCents(product.PriceCents),
+			EndsAt:         sale.EndsAt,
+			PerUserLimit:   sale.PerUserLimit,
+		}
+	}
+}
+
+// attachProductListFlashSales sets FlashSale on each product of a list
+func attachProductListFlashSales(db *gorm.DB, products []models.Product) error {
+	refs := make([]*models.Product, 0, len(products))
+	for i := range products {
+		refs = append(refs, &products[i])
+	}
+	return attachFlashSales(db, refs)
+}
+
+// attachCartFlashSales sets FlashSale on the products of the cart items
+func attachCartFlashSales(db *gorm.DB, items []models.CartItem) error {
+	products := make([]*models.Product, 0, len(items))
+	for _, item := range items {
+		if item.Product != nil {
+			products = append(products, item.Product)
+		}
+	}
+	return attachFlashSales(db, products)
+}
+
+// checkFlashSaleLimits returns errFlashSaleLimit when buying the cart items would take the user past
+// the per-user limit of a flash sale they are priced by. Units in cancelled orders don't count.
+func checkFlashSaleLimits(tx *gorm.DB, userID uuid.UUID, cartItems []models.CartItem) error {
+	for _, item := range cartItems {
+		if !item.Product.OnFlashSale(item.Quantity) || item.Product.FlashSale.PerUserLimit == nil {
+			continue
+		}
+		sale := item.Product.FlashSale
+
+		var bought int64
+		if err := tx.Table("order_items").
+			Joins("JOIN orders ON orders.id = order_items.order_id").
+			Where("orders.user_id = ? AND orders.status <> ? AND order_items.flash_sale_id = ? AND order_items.product_id = ?",
+				userID, models.OrderStatusCancelled, sale.ID, item.ProductID).
+			Select("COALESCE(SUM(order_items.quantity), 0)").
+			Scan(&bought).Error; err != nil {
+			return err
+		}
+		if !sale.AllowsPurchase(int(bought), item.Quantity) {
+			return errFlashSaleLimit
+		}
+	}
+	return nil
+}
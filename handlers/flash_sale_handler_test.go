@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+)
+
+func TestApplyFlashSales(t *testing.T) {
+	endsAt := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	bigSale, smallSale, endedSale := uuid.New(), uuid.New(), uuid.New()
+	mug := &models.Product{ID: uuid.New(), PriceCents: 1000}
+	tee := &models.Product{ID: uuid.New(), PriceCents: 2500}
+	// The tee was priced by a sale that has since ended
+	tee.FlashSale = &models.ProductFlashSale{ID: endedSale, PercentOff: 40, SalePriceCents: 1500}
+
+	// Running sales arrive biggest discount first
+	applyFlashSales([]*models.Product{mug, tee}, []runningFlashSale{
+		{ProductID: mug.ID, ID: bigSale, PercentOff: 30, EndsAt: endsAt, PerUserLimit: intPtr(2)},
+		{ProductID: mug.ID, ID: smallSale, PercentOff: 10, EndsAt: endsAt},
+	})
+
+	if mug.FlashSale == nil || mug.FlashSale.ID != bigSale {
+		t.Fatalf("mug flash sale = %+v, want the 30%% sale", mug.FlashSale)
+	}
+	if mug.FlashSale.SalePriceCents != 700 || !mug.FlashSale.EndsAt.Equal(endsAt) || *mug.FlashSale.PerUserLimit != 2 {
+		t.Errorf("mug flash sale = %+v, want 700 cents until %v with a limit of 2", mug.FlashSale, endsAt)
+	}
+	if got := mug.UnitPriceCents(1); got != 700 {
+		t.Errorf("mug UnitPriceCents(1) = %d, want 700", got)
+	}
+
+	if tee.FlashSale != nil {
+		t.Errorf("tee flash sale = %+v, want the ended sale cleared", tee.FlashSale)
+	}
+	if got := tee.UnitPriceCents(1); got != 2500 {
+		t.Errorf("tee UnitPriceCents(1) = %d, want its base price 2500", got)
+	}
+}
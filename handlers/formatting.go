@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errInvalidFormatted is returned when the formatted query param is not a boolean
+var errInvalidFormatted = errors.New("formatted must be true or false")
+
+// parseFormatted reports whether the formatted query param asks for display prices alongside cents
+func parseFormatted(c *gin.Context) (bool, error) {
+	formatted, err := strconv.ParseBool(c.DefaultQuery("formatted", "false"))
+	if err != nil {
+		return false, errInvalidFormatted
+	}
+	return formatted, nil
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/models"
+)
+
+func TestParseFormatted(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    bool
+		wantErr error
+	}{
+		{"", false, nil},
+		{"?formatted=true", true, nil},
+		{"?formatted=1", true, nil},
+		{"?formatted=false", false, nil},
+		{"?formatted=0", false, nil},
+		{"?formatted=yes", false, errInvalidFormatted},
+		{"?formatted=", false, errInvalidFormatted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/products"+tt.query, nil)
+
+			got, err := parseFormatted(c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseFormatted() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseFormatted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderFormatPrices(t *testing.T) {
+	order := models.Order{
+		TotalCents: 123456,
+		Currency:   "USD",
+		Items: []models.OrderItem{
+			{PriceCents: 1999, Product: &models.Product{PriceCents: 2499, Currency: "USD"}},
+			{PriceCents: 500},
+		},
+	}
+	order.FormatPrices()
+
+	if order.TotalFormatted != "$1,234.56" {
+		t.Errorf("TotalFormatted = %q, want $1,234.56", order.TotalFormatted)
+	}
+	// Items show the price paid, in the order's currency; their products show the current price
+	if got := order.Items[0].PriceFormatted; got != "$19.99" {
+		t.Errorf("item PriceFormatted = %q, want $19.99", got)
+	}
+	if got := order.Items[0].Product.PriceFormatted; got != "$24.99" {
+		t.Errorf("product PriceFormatted = %q, want $24.99", got)
+	}
+	if got := order.Items[1].PriceFormatted; got != "$5.00" {
+		t.Errorf("item without product PriceFormatted = %q, want $5.00", got)
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds a single database ping
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler handles health check endpoints
+type HealthHandler struct {
+	db       *gorm.DB
+	cacheTTL time.Duration
+	ready    atomic.Bool
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	lastErr   error
+}
+
+// NewHealthHandler creates a new health handler.
+// Readiness results are reused for cacheTTL so frequent probes don't each ping the database.
+// The handler reports not ready until MarkReady is called.
+func NewHealthHandler(db *gorm.DB, cacheTTL time.Duration) *HealthHandler {
+	return &HealthHandler{
+		db:       db,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Live reports that the process is up
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"time":   time.Now().UTC(),
+	})
+}
+
+// MarkReady records that server initialization, including migrations, has finished
+func (h *HealthHandler) MarkReady() {
+	h.ready.Store(true)
+}
+
+// IsReady reports whether MarkReady has been called
+func (h *HealthHandler) IsReady() bool {
+	return h.ready.Load()
+}
+
+// Ready reports whether the server has finished initializing and can reach the database
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if !h.IsReady() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "starting",
+			"error":  "server is initializing",
+		})
+		return
+	}
+
+	checkedAt, err := h.checkDatabase(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":     "unavailable",
+			"error":      "database unreachable",
+			"checked_at": checkedAt,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "ok",
+		"checked_at": checkedAt,
+	})
+}
+
+// checkDatabase pings the database unless a result newer than the cache TTL is available.
+// The lock is held during the ping so concurrent probes share a single check.
+func (h *HealthHandler) checkDatabase(ctx context.Context) (time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.checkedAt.IsZero() && time.Since(h.checkedAt) < h.cacheTTL {
+		return h.checkedAt, h.lastErr
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	sqlDB, err := h.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+
+	h.checkedAt = time.Now().UTC()
+	h.lastErr = err
+	return h.checkedAt, err
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHealthHandlerReadyBeforeMarkReady(t *testing.T) {
+	h := NewHealthHandler(dryRunDB(t).DB, time.Minute)
+	if h.IsReady() {
+		t.Fatal("IsReady() = true before MarkReady")
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+	h.Ready(c)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Status != "starting" {
+		t.Errorf("body = %s, want status starting", w.Body.String())
+	}
+
+	h.MarkReady()
+	if !h.IsReady() {
+		t.Error("IsReady() = false after MarkReady")
+	}
+}
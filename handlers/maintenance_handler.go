@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/middleware"
+)
+
+// MaintenanceHandler handles the maintenance mode endpoint
+type MaintenanceHandler struct {
+	maintenance *middleware.Maintenance
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(maintenance *middleware.Maintenance) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenance: maintenance,
+	}
+}
+
+// SetMaintenanceRequest represents maintenance mode toggle input
+type SetMaintenanceRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// SetMaintenance turns maintenance mode on or off (admin only)
+func (h *MaintenanceHandler) SetMaintenance(c *gin.Context) {
+	var req SetMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	h.maintenance.SetEnabled(*req.Enabled)
+	log.Printf("Maintenance mode set to %t", *req.Enabled)
+
+	c.JSON(http.StatusOK, gin.H{
+		"maintenance": *req.Enabled,
+	})
+}
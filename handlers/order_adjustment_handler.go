@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// errOrderNotAdjustable is returned when adjusting an order that is shipped, cancelled or refunded
+	errOrderNotAdjustable = errors.New("order is not adjustable")
+	// errNegativeOrderTotal is returned when an adjustment would take an order's total below zero
+	errNegativeOrderTotal = errors.New("order total cannot be negative")
+)
+
+// adjustableStatuses lists the order statuses whose total can be adjusted
+var adjustableStatuses = map[string]bool{
+	models.OrderStatusPending: true,
+	models.OrderStatusPaid:    true,
+}
+
+// OrderAdjustmentHandler handles manual order total adjustments
+type OrderAdjustmentHandler struct {
+	db *store.DB
+}
+
+// NewOrderAdjustmentHandler creates a new order adjustment handler
+func NewOrderAdjustmentHandler(db *store.DB) *OrderAdjustmentHandler {
+	return &OrderAdjustmentHandler{
+		db: db,
+	}
+}
+
+// CreateOrderAdjustmentRequest represents order adjustment input.
+// AmountCents is signed and cannot be zero.
+type CreateOrderAdjustmentRequest struct {
+	AmountCents int    `json:"amount_cents" binding:"required"`
+	Reason      string `json:"reason" binding:"required,max=500"`
+}
+
+// CreateOrderAdjustment changes a pending or paid order's total by a signed amount (admin only).
+// Shipped, cancelled and refunded orders can't be adjusted. Each adjustment is recorded along with
+// an internal order note, so it shows up in the order's support history.
+func (h *OrderAdjustmentHandler) CreateOrderAdjustment(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	var req CreateOrderAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	var adjustment *models.OrderAdjustment
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		// Locking the order serializes adjustments with each other and with status changes
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			return err
+		}
+		if !adjustableStatuses[order.Status] {
+			return errOrderNotAdjustable
+		}
+		var refunds int64
+		if err := tx.Model(&models.Refund{}).Where("order_id = ?", orderID).Count(&refunds).Error; err != nil {
+			return err
+		}
+		if refunds > 0 {
+			return errOrderNotAdjustable
+		}
+		if order.TotalCents+req.AmountCents < 0 {
+			return errNegativeOrderTotal
+		}
+
+		adjustment = &models.OrderAdjustment{
+			OrderID:     orderID,
+			AmountCents: req.AmountCents,
+			Currency:    order.Currency,
+			Reason:      req.Reason,
+			CreatedBy:   adminID,
+		}
+		if err := tx.Create(adjustment).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&order).Update("total_cents", gorm.Expr("total_cents + ?", req.AmountCents)).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.OrderNote{
+			OrderID:    orderID,
+			AuthorID:   adminID,
+			Body:       fmt.Sprintf("Total adjusted by %s: %s", models.NewMoney(int64(req.AmountCents), order.Currency).Format(), req.Reason),
+			IsInternal: true,
+		}).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+		case errors.Is(err, errOrderNotAdjustable):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "only pending or paid orders without refunds can be adjusted",
+			})
+		case errors.Is(err, errNegativeOrderTotal):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "adjustment would make the order total negative",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to adjust order",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, adjustment)
+}
+
+// ListOrderAdjustments lists an order's adjustments, newest first (admin only)
+func (h *OrderAdjustmentHandler) ListOrderAdjustments(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	var adjustments []models.OrderAdjustment
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("order_id = ?", orderID).
+		Order("created_at DESC").
+		Find(&adjustments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list adjustments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"adjustments": adjustments,
+	})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errOrderNotPending is returned when discounting an order that is no longer pending
+var errOrderNotPending = errors.New("order is not pending")
+
+// ApplyOrderDiscountRequest represents order discount input.
+// Value is the percent off (1-100) for percent discounts and the amount in cents for fixed ones.
+type ApplyOrderDiscountRequest struct {
+	Type   string `json:"type" binding:"required,oneof=percent fixed"`
+	Value  int    `json:"value" binding:"required,min=1"`
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ApplyOrderDiscount grants a one-off percent or fixed discount on a pending order (admin only).
+// A percent discount is taken from the order's item subtotal. The amount comes off the total,
+// which can't go below zero. An order gets at most one discount, and paid, shipped and cancelled
+// orders can't be discounted. The discount is also recorded as an internal order note.
+func (h *OrderAdjustmentHandler) ApplyOrderDiscount(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	var req ApplyOrderDiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+	if req.Type == models.OrderDiscountPercent && req.Value > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "a percent discount can be at most 100",
+		})
+		return
+	}
+
+	var discount *models.OrderDiscount
+	var order models.Order
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		// Locking the order serializes the discount with payment and other total changes
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			return err
+		}
+		if order.Status != models.OrderStatusPending {
+			return errOrderNotPending
+		}
+
+		var items []models.OrderItem
+		if req.Type == models.OrderDiscountPercent {
+			if err := tx.Where("order_id = ?", orderID).Find(&items).Error; err != nil {
+				return err
+			}
+		}
+		amount := orderDiscountAmount(req.Type, req.Value, items)
+		if order.TotalCents-amount < 0 {
+			return errNegativeOrderTotal
+		}
+
+		discount = &models.OrderDiscount{
+			OrderID:     orderID,
+			Type:        req.Type,
+			Value:       req.Value,
+			AmountCents: amount,
+			Currency:    order.Currency,
+			Reason:      req.Reason,
+			CreatedBy:   adminID,
+		}
+		if err := tx.Create(discount).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&order).Update("total_cents", gorm.Expr("total_cents - ?", amount)).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.OrderNote{
+			OrderID:    orderID,
+			AuthorID:   adminID,
+			Body:       fmt.Sprintf("Discount of %s applied: %s", models.NewMoney(int64(amount), order.Currency).Format(), req.Reason),
+			IsInternal: true,
+		}).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+		case errors.Is(err, errOrderNotPending):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "only pending orders can be discounted",
+			})
+		case errors.Is(err, errNegativeOrderTotal):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "discount would make the order total negative",
+			})
+		case isUniqueViolation(err):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "order already has a discount",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to apply discount",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"discount":    discount,
+		"total_cents": order.TotalCents - discount.AmountCents,
+	})
+}
+
+// orderDiscountAmount returns the cents a discount takes off an order with the given items.
+// A percent discount is taken from the sum of the item line totals, rounded half up to the nearest cent.
+func orderDiscountAmount(discountType string, value int, items []models.OrderItem) int {
+	if discountType != models.OrderDiscountPercent {
+		return value
+	}
+	var subtotal int64
+	for i := range items {
+		subtotal += items[i].LineTotalCents(items[i].Quantity)
+	}
+	return int((subtotal*int64(value) + 50) / 100)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/sainudheenp/goecom/models"
+)
+
+func TestOrderDiscountAmount(t *testing.T) {
+	items := []models.OrderItem{
+		{PriceCents: 1299, Quantity: 2, UnitType: models.UnitTypeEach},
+		// 750 g at 1999 per kilogram is 1499.25, which rounds to 1499
+		{PriceCents: 1999, Quantity: 750, UnitType: models.UnitTypeWeight},
+	}
+	tests := []struct {
+		name         string
+		discountType string
+		value        int
+		items        []models.OrderItem
+		want         int
+	}{
+		{"percent of the line totals", models.OrderDiscountPercent, 10, items, 410},
+		{"whole order", models.OrderDiscountPercent, 100, items, 4097},
+		{"rounds half up", models.OrderDiscountPercent, 50, []models.OrderItem{{PriceCents: 1, Quantity: 1, UnitType: models.UnitTypeEach}}, 1},
+		{"percent of no items", models.OrderDiscountPercent, 10, nil, 0},
+		{"fixed", models.OrderDiscountFixed, 500, items, 500},
+		{"fixed ignores the items", models.OrderDiscountFixed, 500, nil, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderDiscountAmount(tt.discountType, tt.value, tt.items); got != tt.want {
+				t.Errorf("orderDiscountAmount(%s, %d) = %d, want %d", tt.discountType, tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1502 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/internal/webhook"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// errEmptyCart is returned when an order is placed with an empty cart
+	errEmptyCart = errors.New("cart is empty")
+	// errProductUnavailable is returned when an order is placed for a product that isn't published
+	errProductUnavailable = errors.New("product is not available")
+	// errInvalidTransition is returned when an order status change is not allowed
+	errInvalidTransition = errors.New("invalid status transition")
+	// errAddressNotFound is returned when a saved address does not exist for the user
+	errAddressNotFound = errors.New("address not found")
+	// errAddressRequired is returned when no shipping address can be determined
+	errAddressRequired = errors.New("shipping address required")
+	// errOrderNotFound is returned when an order does not exist
+	errOrderNotFound = errors.New("order not found")
+	// errOrderForbidden is returned when an order exists but belongs to another user
+	errOrderForbidden = errors.New("order belongs to another user")
+)
+
+// orderIncludes maps the allowed include values to the relations they preload
+var orderIncludes = map[string]string{
+	"items":         "Items",
+	"items.product": "Items.Product",
+}
+
+// orderStatuses lists every order status in lifecycle order
+var orderStatuses = []string{
+	models.OrderStatusPending,
+	models.OrderStatusPaid,
+	models.OrderStatusShipped,
+	models.OrderStatusCancelled,
+}
+
+// orderStatusTransitions defines which statuses an order may move to from its current status
+var orderStatusTransitions = map[string][]string{
+	models.OrderStatusPending: {models.OrderStatusPaid, models.OrderStatusCancelled},
+	models.OrderStatusPaid:    {models.OrderStatusShipped, models.OrderStatusCancelled},
+}
+
+// OrderHandler handles order endpoints
+type OrderHandler struct {
+	db            *store.DB
+	cartLimits    CartLimits
+	holdTTL       time.Duration
+	pageSize      int
+	events        *webhook.Publisher
+	statusWatcher *orderStatusWatcher
+}
+
+// NewOrderHandler creates a new order handler.
+// It takes the store so checkout transactions are retried on deadlocks;
+// checkout previews hold stock for holdTTL (0 disables holds) and pageSize is the default size of order lists.
+// Order lists read from the store's reader; single orders are read from the primary, since
+// they are usually fetched right after being placed or updated.
+func NewOrderHandler(db *store.DB, cartLimits CartLimits, holdTTL time.Duration, pageSize int, events *webhook.Publisher) *OrderHandler {
+	return &OrderHandler{
+		db:            db,
+		cartLimits:    cartLimits,
+		holdTTL:       holdTTL,
+		pageSize:      pageSize,
+		events:        events,
+		statusWatcher: newOrderStatusWatcher(),
+	}
+}
+
+// OrderCreatedEvent is the webhook payload sent when an order is placed
+type OrderCreatedEvent struct {
+	OrderID     uuid.UUID `json:"order_id"`
+	OrderNumber string    `json:"order_number"`
+	UserID      uuid.UUID `json:"user_id"`
+	Status      string    `json:"status"`
+	TotalCents  int       `json:"total_cents"`
+	Currency    string    `json:"currency"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OrderStatusChangedEvent is the webhook payload sent when an order changes status
+type OrderStatusChangedEvent struct {
+	OrderID   uuid.UUID `json:"order_id"`
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	ChangedBy uuid.UUID `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ShippingAddress represents an inline shipping address
+type ShippingAddress struct {
+	Line1    string `json:"line1" binding:"required"`
+	Line2    string `json:"line2"`
+	City     string `json:"city" binding:"required"`
+	State    string `json:"state"`
+	Country  string `json:"country" binding:"required"`
+	Postcode string `json:"postcode" binding:"required"`
+}
+
+// toJSONMap converts the address into the form stored on an order
+func (a *ShippingAddress) toJSONMap() models.JSONMap {
+	return models.JSONMap{
+		"line1":    a.Line1,
+		"line2":    a.Line2,
+		"city":     a.City,
+		"state":    a.State,
+		"country":  a.Country,
+		"postcode": a.Postcode,
+	}
+}
+
+// CreateOrderRequest represents order creation input.
+// Either an inline shipping address or a saved address ID may be given;
+// when neither is, the user's default address is used.
+type CreateOrderRequest struct {
+	ShippingAddress *ShippingAddress `json:"shipping_address"`
+	AddressID       *uuid.UUID       `json:"address_id"`
+	IsGift          bool             `json:"is_gift"`
+	GiftMessage     string           `json:"gift_message" binding:"max=500"`
+	Metadata        models.JSONMap   `json:"metadata"`
+}
+
+const (
+	// maxOrderMetadataKeys caps the number of keys in an order's metadata
+	maxOrderMetadataKeys = 20
+	// maxOrderMetadataKeyLength caps the length of a metadata key
+	maxOrderMetadataKeyLength = 40
+	// maxOrderMetadataValueLength caps the length of a string metadata value
+	maxOrderMetadataValueLength = 500
+)
+
+// ClientSourceHeader names the channel a request was sent from; it is recorded as the order source
+const ClientSourceHeader = "X-Client-Source"
+
+// orderSource returns the order source named by the X-Client-Source header, defaulting to web
+func orderSource(c *gin.Context) (string, error) {
+	source := strings.ToLower(strings.TrimSpace(c.GetHeader(ClientSourceHeader)))
+	if source == "" {
+		return models.OrderSourceWeb, nil
+	}
+	if !models.IsValidOrderSource(source) {
+		return "", fmt.Errorf("%s must be one of %s", ClientSourceHeader, strings.Join(models.OrderSources, ", "))
+	}
+	return source, nil
+}
+
+// reservedOrderMetadataKeys are typed order fields that must not be set through metadata
+var reservedOrderMetadataKeys = map[string]bool{
+	"is_gift":      true,
+	"gift_message": true,
+}
+
+// validateOrderMetadata checks that metadata is a small, flat map of strings, numbers and booleans
+func validateOrderMetadata(metadata models.JSONMap) error {
+	if len(metadata) > maxOrderMetadataKeys {
+		return fmt.Errorf("metadata must have at most %d keys", maxOrderMetadataKeys)
+	}
+	for key, value := range metadata {
+		if key == "" || utf8.RuneCountInString(key) > maxOrderMetadataKeyLength {
+			return fmt.Errorf("metadata keys must be 1-%d characters", maxOrderMetadataKeyLength)
+		}
+		if reservedOrderMetadataKeys[key] {
+			return fmt.Errorf("metadata key %q is reserved, use the %s field instead", key, key)
+		}
+		switch v := value.(type) {
+		case string:
+			if utf8.RuneCountInString(v) > maxOrderMetadataValueLength {
+				return fmt.Errorf("metadata value for %q must be at most %d characters", key, maxOrderMetadataValueLength)
+			}
+		case float64, bool:
+		default:
+			return fmt.Errorf("metadata value for %q must be a string, number or boolean", key)
+		}
+	}
+	return nil
+}
+
+// CreateOrder creates an order from the user's cart
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	if req.AddressID != nil && req.ShippingAddress != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "provide either shipping_address or address_id, not both",
+		})
+		return
+	}
+
+	if req.GiftMessage != "" && !req.IsGift {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "gift_message requires is_gift",
+		})
+		return
+	}
+
+	if err := validateOrderMetadata(req.Metadata); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	source, err := orderSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var placed *models.Order
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		// Each attempt builds its own order, so a retried transaction doesn't start from one
+		// filled in by an attempt that rolled back
+		order := &models.Order{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Status:      models.OrderStatusPending,
+			Source:      source,
+			IsGift:      req.IsGift,
+			GiftMessage: strings.TrimSpace(req.GiftMessage),
+			Metadata:    req.Metadata,
+		}
+
+		if err := lockCheckout(tx, userID); err != nil {
+			return err
+		}
+
+		shippingAddress, err := resolveShippingAddress(tx, userID, &req)
+		if err != nil {
+			return err
+		}
+		order.ShippingAddress = shippingAddress
+
+		var cartItems []models.CartItem
+		if err := tx.Preload("Product.PriceTiers").Where("user_id = ?", userID).Find(&cartItems).Error; err != nil {
+			return err
+		}
+		if len(cartItems) == 0 {
+			return errEmptyCart
+		}
+		// Products unpublished since they were added can't be sold, as ValidateCart reports
+		for _, item := range cartItems {
+			if item.Product == nil || item.Product.Status != models.ProductStatusPublished {
+				return errProductUnavailable
+			}
+		}
+		if err := attachCartFlashSales(tx, cartItems); err != nil {
+			return err
+		}
+
+		items, total, err := priceCartItems(cartItems)
+		if err != nil {
+			return err
+		}
+		if err := checkFlashSaleLimits(tx, userID, cartItems); err != nil {
+			return err
+		}
+
+		// Stock other shoppers hold during their checkout isn't for sale; the user's own holds are converted
+		productIDs := make([]uuid.UUID, 0, len(items))
+		for _, item := range items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		held, err := heldByOthers(tx, productIDs, userID)
+		if err != nil {
+			return err
+		}
+		for i, item := range items {
+			if cartItems[i].Product.Stock-held[item.ProductID] < item.Quantity {
+				return errInsufficientStock
+			}
+			if err := decrementStock(tx, item.ProductID, item.Quantity, models.StockReasonOrder, &order.ID); err != nil {
+				return err
+			}
+		}
+		if err := releaseStockHolds(tx, userID); err != nil {
+			return err
+		}
+
+		order.Items = items
+		order.TotalCents = int(total.AmountCents)
+		order.Currency = total.Currency
+
+		if order.OrderNumber, err = nextOrderNumber(tx); err != nil {
+			return err
+		}
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+
+		if err := h.events.Enqueue(tx, webhook.EventOrderCreated, OrderCreatedEvent{
+			OrderID:     order.ID,
+			OrderNumber: order.OrderNumber,
+			UserID:      order.UserID,
+			Status:      order.Status,
+			TotalCents:  order.TotalCents,
+			Currency:    order.Currency,
+			CreatedAt:   order.CreatedAt,
+		}); err != nil {
+			return err
+		}
+
+		placed = order
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmptyCart):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "cart is empty",
+			})
+		case errors.Is(err, errProductUnavailable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": "cart contains products that are no longer available",
+			})
+		case errors.Is(err, errAddressNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "address not found",
+			})
+		case errors.Is(err, errAddressRequired):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "shipping_address or address_id is required",
+			})
+		case errors.Is(err, errInsufficientStock):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "insufficient stock",
+			})
+		case errors.Is(err, models.ErrCurrencyMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "cart contains items in multiple currencies",
+			})
+		case errors.Is(err, errFlashSaleLimit):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "cart exceeds a flash sale's per-customer limit",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to create order",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, placed)
+}
+
+// OrderPreviewItem is one priced line of a checkout preview
+type OrderPreviewItem struct {
+	ProductID      uuid.UUID `json:"product_id"`
+	Name           string    `json:"name"`
+	PriceCents     int       `json:"price_cents"`
+	Quantity       int       `json:"quantity"`
+	LineTotalCents int       `json:"line_total_cents"`
+	InStock        bool      `json:"in_stock"`
+}
+
+// OrderPreview is the order CreateOrder would place for the current cart.
+// The package weight and volume are the inputs for shipping calculation.
+type OrderPreview struct {
+	Items              []OrderPreviewItem `json:"items"`
+	SubtotalCents      int                `json:"subtotal_cents"`
+	TotalCents         int                `json:"total_cents"`
+	Currency           string             `json:"currency"`
+	ShippingAddress    models.JSONMap     `json:"shipping_address"`
+	CanPlace           bool               `json:"can_place"`
+	PackageWeightGrams int                `json:"package_weight_grams"`
+	PackageVolumeCm3   int                `json:"package_volume_cm3"`
+	HoldExpiresAt      *time.Time         `json:"hold_expires_at,omitempty"`
+}
+
+// PreviewOrder prices the user's cart the same way CreateOrder does without saving the order.
+// can_place is false when an item is short of stock. When holds are enabled, the in-stock items
+// are held for the user until hold_expires_at, replacing any holds from an earlier preview.
+func (h *OrderHandler) PreviewOrder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	if req.AddressID != nil && req.ShippingAddress != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "provide either shipping_address or address_id, not both",
+		})
+		return
+	}
+
+	preview, err := h.previewOrder(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, errEmptyCart):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "cart is empty",
+			})
+		case errors.Is(err, errAddressNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "address not found",
+			})
+		case errors.Is(err, errAddressRequired):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "shipping_address or address_id is required",
+			})
+		case errors.Is(err, models.ErrCurrencyMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "cart contains items in multiple currencies",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to preview order",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// ListOrders lists the current user's orders
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	h.listUserOrders(c, userID)
+}
+
+// ListUserOrders lists any user's orders for support (admin only)
+func (h *OrderHandler) ListUserOrders(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	// Deactivated users are included so support can still look them up
+	var count int64
+	if err := h.db.WithContext(c.Request.Context()).Unscoped().Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get user",
+		})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
+		})
+		return
+	}
+
+	h.listUserOrders(c, userID)
+}
+
+// listUserOrders writes a page of a user's orders, applying the list filters and includes
+func (h *OrderHandler) listUserOrders(c *gin.Context, userID uuid.UUID) {
+	page, size, err := parsePagination(c, h.pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	formatted, err := parseFormatted(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbQuery, err := applyOrderFilters(h.db.Reader().WithContext(c.Request.Context()).Model(&models.Order{}).Where("user_id = ?", userID), c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count orders",
+		})
+		return
+	}
+
+	findQuery, err := applyOrderIncludes(dbQuery, c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var orders []models.Order
+	offset := (page - 1) * size
+	if err := findQuery.Order("created_at DESC").Limit(size).Offset(offset).Find(&orders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list orders",
+		})
+		return
+	}
+
+	if formatted {
+		for i := range orders {
+			orders[i].FormatPrices()
+		}
+	}
+
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, gin.H{
+		"orders": orders,
+		"total":  total,
+		"page":   page,
+		"size":   size,
+	})
+}
+
+// GetOrder retrieves one of the current user's orders by ID.
+// With wait=N it long-polls: the response is held for up to N seconds (capped at 30) until the
+// order's status changes, then returns the order as it is.
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	formatted, err := parseFormatted(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	wait := 0
+	if v := c.Query("wait"); v != "" {
+		if wait, err = strconv.Atoi(v); err != nil || wait < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "wait must be a non-negative number of seconds",
+			})
+			return
+		}
+		wait = min(wait, maxOrderWaitSeconds)
+	}
+
+	dbQuery, err := applyOrderIncludes(h.db.WithContext(c.Request.Context()), c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	dbQuery = dbQuery.Session(&gorm.Session{})
+	orderQuery := dbQuery.Where("user_id = ?", userID).Session(&gorm.Session{})
+
+	// Watch before loading so a change right after the load isn't missed
+	var changed <-chan struct{}
+	if wait > 0 {
+		var release func()
+		changed, release = h.statusWatcher.watch(id)
+		defer release()
+	}
+
+	order, err := findOrder(dbQuery, id, &userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errOrderNotFound), errors.Is(err, errOrderForbidden):
+			// Another user's order is reported as missing so order IDs can't be probed
+			if errors.Is(err, errOrderForbidden) {
+				log.Printf("User %s requested order %s of another user", userID, id)
+			}
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to get order",
+			})
+		}
+		return
+	}
+
+	if wait > 0 {
+		if err := waitForStatusChange(c.Request.Context(), orderQuery, order, changed, time.Duration(wait)*time.Second); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to get order",
+			})
+			return
+		}
+	}
+
+	if formatted {
+		order.FormatPrices()
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// GetAnyOrder retrieves any user's order by ID, along with the user who placed it (admin only)
+func (h *OrderHandler) GetAnyOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	formatted, err := parseFormatted(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbQuery, err := applyOrderIncludes(h.db.WithContext(c.Request.Context()), c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	dbQuery = dbQuery.Preload("User", func(db *gorm.DB) *gorm.DB {
+		// Orders of deactivated users still show who placed them
+		return db.Unscoped()
+	})
+
+	order, err := findOrder(dbQuery, id, nil)
+	if err != nil {
+		if errors.Is(err, errOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get order",
+		})
+		return
+	}
+
+	if formatted {
+		order.FormatPrices()
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// findOrder loads an order by ID through dbQuery. It returns errOrderNotFound when the order
+// doesn't exist and, when ownerID is set, errOrderForbidden when it belongs to another user.
+func findOrder(dbQuery *gorm.DB, id uuid.UUID, ownerID *uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	if err := dbQuery.First(&order, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errOrderNotFound
+		}
+		return nil, err
+	}
+	if ownerID != nil && order.UserID != *ownerID {
+		return nil, errOrderForbidden
+	}
+	return &order, nil
+}
+
+// GetOrderByNumber retrieves one of the current user's orders by its order number
+func (h *OrderHandler) GetOrderByNumber(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	h.getOrderByNumber(c, h.db.WithContext(c.Request.Context()).Where("user_id = ?", userID))
+}
+
+// GetAnyOrderByNumber retrieves any user's order by its order number (admin only)
+func (h *OrderHandler) GetAnyOrderByNumber(c *gin.Context) {
+	h.getOrderByNumber(c, h.db.WithContext(c.Request.Context()))
+}
+
+// getOrderByNumber writes the order numbered by the :number param, looked up through dbQuery.
+// Numbers are matched case-insensitively.
+func (h *OrderHandler) getOrderByNumber(c *gin.Context, dbQuery *gorm.DB) {
+	number := strings.ToUpper(strings.TrimSpace(c.Param("number")))
+
+	formatted, err := parseFormatted(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbQuery, err = applyOrderIncludes(dbQuery, c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var order models.Order
+	if err := dbQuery.Where("order_number = ?", number).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get order",
+		})
+		return
+	}
+
+	if formatted {
+		order.FormatPrices()
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// GetOrderSummary returns the current user's order counts by status and lifetime spend
+func (h *OrderHandler) GetOrderSummary(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+
+	var statusCounts []struct {
+		Status string
+		Count  int64
+	}
+	if err := db.Model(&models.Order{}).
+		Select("status, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("status").
+		Scan(&statusCounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to summarize orders",
+		})
+		return
+	}
+
+	var spend []models.Money
+	if err := db.Model(&models.Order{}).
+		Select("currency, COALESCE(SUM(total_cents), 0) AS amount_cents").
+		Where("user_id = ? AND status IN ?", userID, revenueStatuses).
+		Group("currency").
+		Order("currency").
+		Scan(&spend).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to summarize orders",
+		})
+		return
+	}
+
+	byStatus := map[string]int64{
+		models.OrderStatusPending:   0,
+		models.OrderStatusPaid:      0,
+		models.OrderStatusShipped:   0,
+		models.OrderStatusCancelled: 0,
+	}
+	var totalOrders int64
+	for _, sc := range statusCounts {
+		byStatus[sc.Status] = sc.Count
+		totalOrders += sc.Count
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total_orders": totalOrders,
+		"by_status":    byStatus,
+		"total_spent":  spend,
+	})
+}
+
+// Reorder adds the items of one of the user's past orders to their cart.
+// Quantities are capped at available stock and unavailable items are reported as skipped.
+func (h *OrderHandler) Reorder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	var results []CartItemResult
+	var cart *CartResponse
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		// Reset state built by a previous attempt
+		results = nil
+
+		var order models.Order
+		// Products no longer published aren't loaded and are reported missing by addManyToCart
+		if err := tx.Preload("Items.Product", "status = ?", models.ProductStatusPublished).Where("user_id = ?", userID).First(&order, id).Error; err != nil {
+			return err
+		}
+
+		productIDs := make([]uuid.UUID, 0, len(order.Items))
+		for _, item := range order.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		held, err := heldByOthers(tx, productIDs, userID)
+		if err != nil {
+			return err
+		}
+
+		inputs := make([]CartItemInput, 0, len(order.Items))
+		for _, item := range order.Items {
+			quantity := item.Quantity
+			if item.Product != nil {
+				quantity = min(quantity, h.cartLimits.maxQuantity(item.Product), item.Product.Stock-held[item.ProductID])
+			}
+			if quantity <= 0 {
+				results = append(results, CartItemResult{
+					ProductID: item.ProductID,
+					Quantity:  item.Quantity,
+					Error:     "out of stock",
+				})
+				continue
+			}
+			inputs = append(inputs, CartItemInput{
+				ProductID: item.ProductID,
+				Quantity:  quantity,
+			})
+		}
+
+		if len(inputs) > 0 {
+			added, err := addManyToCart(tx, userID, inputs, h.cartLimits)
+			if err != nil {
+				return err
+			}
+			results = append(results, added...)
+		}
+
+		cart, err = loadCart(tx, userID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to reorder",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cart":    cart,
+		"results": results,
+	})
+}
+
+// ListAllOrders lists orders across all users (admin only)
+func (h *OrderHandler) ListAllOrders(c *gin.Context) {
+	page, size, err := parsePagination(c, h.pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	formatted, err := parseFormatted(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbQuery, err := applyOrderFilters(h.db.Reader().WithContext(c.Request.Context()).Model(&models.Order{}), c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count orders",
+		})
+		return
+	}
+
+	// Preloads load each relation for the whole page in one IN query, so a page takes the same
+	// five queries (count, orders, items, products, users) whatever its size. Anything derived
+	// per order or item should come from these or be batched the same way, not looked up in a loop.
+	var orders []models.Order
+	offset := (page - 1) * size
+	if err := dbQuery.Preload("Items.Product").Preload("User", func(db *gorm.DB) *gorm.DB {
+		// Orders of deactivated users still show who placed them
+		return db.Unscoped()
+	}).Order("created_at DESC").Limit(size).Offset(offset).Find(&orders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list orders",
+		})
+		return
+	}
+
+	if formatted {
+		for i := range orders {
+			orders[i].FormatPrices()
+		}
+	}
+
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, gin.H{
+		"orders": orders,
+		"total":  total,
+		"page":   page,
+		"size":   size,
+	})
+}
+
+// ListOrderStatuses returns every order status with the statuses each may move to, as enforced
+// by UpdateOrderStatus. With current=<status> it returns only that status's next statuses.
+func (h *OrderHandler) ListOrderStatuses(c *gin.Context) {
+	if current, ok := c.GetQuery("current"); ok {
+		if !slices.Contains(orderStatuses, current) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("current must be one of %s", strings.Join(orderStatuses, ", ")),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status": current,
+			"next":   nextOrderStatuses(current),
+		})
+		return
+	}
+
+	transitions := make(map[string][]string, len(orderStatuses))
+	for _, status := range orderStatuses {
+		transitions[status] = nextOrderStatuses(status)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"statuses":    orderStatuses,
+		"transitions": transitions,
+	})
+}
+
+// UpdateOrderStatusRequest represents order status update input
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending paid shipped cancelled"`
+}
+
+// UpdateOrderStatus changes an order's status (admin only).
+// An order.status_changed webhook is written to the outbox with the change.
+func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	var req UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var oldStatus string
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		var err error
+		if oldStatus, err = setOrderStatus(tx, id, req.Status); err != nil {
+			return err
+		}
+
+		return h.events.Enqueue(tx, webhook.EventOrderStatusChanged, OrderStatusChangedEvent{
+			OrderID:   id,
+			OldStatus: oldStatus,
+			NewStatus: req.Status,
+			ChangedBy: adminID,
+			ChangedAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+		case errors.Is(err, errInvalidTransition):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid status transition",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to update order status",
+			})
+		}
+		return
+	}
+
+	h.statusWatcher.notify(id)
+	if req.Status == models.OrderStatusCancelled {
+		notifyBackInStock(c.Request.Context(), h.db, h.events)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "order status updated",
+	})
+}
+
+// BulkUpdateOrderStatusRequest represents bulk order status update input
+type BulkUpdateOrderStatusRequest struct {
+	OrderIDs []uuid.UUID `json:"order_ids" binding:"required,min=1,max=100"`
+	Status   string      `json:"status" binding:"required,oneof=pending paid shipped cancelled"`
+}
+
+// OrderStatusResult reports the outcome for one order of a bulk status update
+type OrderStatusResult struct {
+	OrderID   uuid.UUID `json:"order_id"`
+	OldStatus string    `json:"old_status,omitempty"`
+	Updated   bool      `json:"updated"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// BulkUpdateOrderStatus changes the status of several orders in one transaction (admin only).
+// Orders that can't be updated are reported individually without failing the others.
+func (h *OrderHandler) BulkUpdateOrderStatus(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	var req BulkUpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	var results []OrderStatusResult
+	changedAt := time.Now().UTC()
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		// Reset state built by a previous attempt
+		results = make([]OrderStatusResult, 0, len(req.OrderIDs))
+
+		for i, id := range req.OrderIDs {
+			result := OrderStatusResult{OrderID: id}
+
+			// A savepoint per order keeps a failed order's partial writes out of the batch
+			savepoint := fmt.Sprintf("order_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+
+			oldStatus, err := setOrderStatus(tx, id, req.Status)
+			switch {
+			case err == nil:
+				result.OldStatus = oldStatus
+				result.Updated = true
+				if err := h.events.Enqueue(tx, webhook.EventOrderStatusChanged, OrderStatusChangedEvent{
+					OrderID:   id,
+					OldStatus: oldStatus,
+					NewStatus: req.Status,
+					ChangedBy: adminID,
+					ChangedAt: changedAt,
+				}); err != nil {
+					return err
+				}
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				result.Error = "order not found"
+			case errors.Is(err, errInvalidTransition):
+				result.OldStatus = oldStatus
+				result.Error = "invalid status transition"
+			default:
+				return err
+			}
+			if !result.Updated {
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+			}
+
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update order statuses",
+		})
+		return
+	}
+
+	for _, result := range results {
+		if result.Updated {
+			h.statusWatcher.notify(result.OrderID)
+		}
+	}
+	if req.Status == models.OrderStatusCancelled {
+		notifyBackInStock(c.Request.Context(), h.db, h.events)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+	})
+}
+
+// setOrderStatus moves a locked order to a new status, returning stock when it is cancelled.
+// It returns the order's previous status and must be called with a transaction.
+func setOrderStatus(tx *gorm.DB, id uuid.UUID, status string) (string, error) {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&order, id).Error; err != nil {
+		return "", err
+	}
+
+	if !canTransition(order.Status, status) {
+		return order.Status, errInvalidTransition
+	}
+
+	// Return stock for cancelled orders, leaving out units a refund has already restocked
+	if status == models.OrderStatusCancelled {
+		refunded, err := refundedQuantities(tx, order.ID)
+		if err != nil {
+			return order.Status, err
+		}
+		for _, item := range order.Items {
+			quantity := unrefundedQuantity(item, refunded)
+			if quantity == 0 {
+				continue
+			}
+			if err := incrementStock(tx, item.ProductID, quantity, models.StockReasonCancel, &order.ID); err != nil {
+				return order.Status, err
+			}
+		}
+	}
+
+	return order.Status, tx.Model(&order).Update("status", status).Error
+}
+
+// previewOrder builds the user's checkout preview and holds the stock of its in-stock items
+func (h *OrderHandler) previewOrder(ctx context.Context, userID uuid.UUID, req *CreateOrderRequest) (*OrderPreview, error) {
+	var preview *OrderPreview
+	err := h.db.WithTransaction(ctx, func(tx *gorm.DB) error {
+		// Serializes with the user's checkouts and other previews, which replace the same holds
+		if err := lockCheckout(tx, userID); err != nil {
+			return err
+		}
+
+		var err error
+		if preview, err = buildOrderPreview(tx, userID, req); err != nil {
+			return err
+		}
+		if h.holdTTL <= 0 {
+			return nil
+		}
+
+		items := make([]models.OrderItem, 0, len(preview.Items))
+		for _, item := range preview.Items {
+			if item.InStock {
+				items = append(items, models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+			}
+		}
+		expiresAt, err := placeStockHolds(tx, userID, items, h.holdTTL)
+		if err != nil {
+			return err
+		}
+		if len(items) > 0 {
+			preview.HoldExpiresAt = &expiresAt
+		}
+		return nil
+	})
+	return preview, err
+}
+
+// buildOrderPreview prices the user's cart and checks stock, net of other shoppers' holds, without writing anything
+func buildOrderPreview(db *gorm.DB, userID uuid.UUID, req *CreateOrderRequest) (*OrderPreview, error) {
+	shippingAddress, err := resolveShippingAddress(db, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var cartItems []models.CartItem
+	if err := db.Preload("Product.PriceTiers").Where("user_id = ?", userID).Order("created_at ASC").Find(&cartItems).Error; err != nil {
+		return nil, err
+	}
+	if len(cartItems) == 0 {
+		return nil, errEmptyCart
+	}
+	if err := attachCartFlashSales(db, cartItems); err != nil {
+		return nil, err
+	}
+
+	items, total, err := priceCartItems(cartItems)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	held, err := heldByOthers(db, productIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &OrderPreview{
+		Items:           make([]OrderPreviewItem, 0, len(items)),
+		SubtotalCents:   int(total.AmountCents),
+		TotalCents:      int(total.AmountCents),
+		Currency:        total.Currency,
+		ShippingAddress: shippingAddress,
+		CanPlace:        true,
+	}
+	preview.PackageWeightGrams, preview.PackageVolumeCm3 = cartPackage(cartItems)
+	for i, item := range items {
+		inStock := cartItems[i].Product.Stock-held[item.ProductID] >= item.Quantity
+		preview.CanPlace = preview.CanPlace && inStock
+		preview.Items = append(preview.Items, OrderPreviewItem{
+			ProductID:      item.ProductID,
+			Name:           cartItems[i].Product.Name,
+			PriceCents:     item.PriceCents,
+			Quantity:       item.Quantity,
+			LineTotalCents: int(item.LineTotalCents(item.Quantity)),
+			InStock:        inStock,
+		})
+	}
+	return preview, nil
+}
+
+// cartPackage sums the shipping weight and volume of the cart items' products.
+// The volume is rounded up to whole cubic centimetres.
+func cartPackage(cartItems []models.CartItem) (weightGrams, volumeCm3 int) {
+	var volumeMm3 int64
+	for _, item := range cartItems {
+		weightGrams += item.Product.PackageWeightGrams(item.Quantity)
+		volumeMm3 += item.Product.PackageVolumeMm3(item.Quantity)
+	}
+	return weightGrams, int((volumeMm3 + 999) / 1000)
+}
+
+// priceCartItems turns cart items into order items at the products' current prices and totals them.
+// Volume discounts are applied, so cart items must be loaded with their products' price tiers.
+// Checkout and the checkout preview both use it so a preview always matches the placed order.
+func priceCartItems(cartItems []models.CartItem) ([]models.OrderItem, models.Money, error) {
+	var total models.Money
+	items := make([]models.OrderItem, 0, len(cartItems))
+	for _, item := range cartItems {
+		if item.Product == nil {
+			return nil, models.Money{}, gorm.ErrRecordNotFound
+		}
+
+		var err error
+		if total, err = total.Add(item.Product.LineTotal(item.Quantity)); err != nil {
+			return nil, models.Money{}, err
+		}
+		orderItem := models.OrderItem{
+			ProductID:  item.ProductID,
+			PriceCents: item.Product.UnitPriceCents(item.Quantity),
+			Quantity:   item.Quantity,
+			UnitType:   item.Product.UnitType,
+		}
+		if item.Product.OnFlashSale(item.Quantity) {
+			orderItem.FlashSaleID = &item.Product.FlashSale.ID
+		}
+		items = append(items, orderItem)
+	}
+	return items, total, nil
+}
+
+// lockCheckout takes a transaction-scoped advisory lock on the user's checkout.
+// Concurrent checkouts by the same user wait for each other, so a second checkout
+// only reads the cart after the first one has emptied it.
+func lockCheckout(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", "checkout:"+userID.String()).Error
+}
+
+// nextOrderNumber draws the next order number from the order_number_seq sequence.
+// Sequence values are never handed out twice, so concurrent checkouts get distinct numbers;
+// a rolled back checkout leaves a gap.
+func nextOrderNumber(tx *gorm.DB) (string, error) {
+	var seq int64
+	if err := tx.Raw("SELECT nextval('order_number_seq')").Scan(&seq).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ORD-%d-%06d", time.Now().UTC().Year(), seq), nil
+}
+
+// resolveShippingAddress determines the shipping address for a new order.
+// Saved addresses are snapshotted so later edits don't change past orders.
+func resolveShippingAddress(tx *gorm.DB, userID uuid.UUID, req *CreateOrderRequest) (models.JSONMap, error) {
+	if req.ShippingAddress != nil {
+		return req.ShippingAddress.toJSONMap(), nil
+	}
+
+	var address models.Address
+	query := tx.Where("user_id = ?", userID)
+	if req.AddressID != nil {
+		query = query.Where("id = ?", *req.AddressID)
+	} else {
+		query = query.Where("is_default")
+	}
+
+	if err := query.First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if req.AddressID != nil {
+				return nil, errAddressNotFound
+			}
+			return nil, errAddressRequired
+		}
+		return nil, err
+	}
+
+	return address.Snapshot(), nil
+}
+
+// applyOrderFilters narrows an order query by the status, from and to query params.
+// Dates are YYYY-MM-DD and inclusive; the returned error is safe to show to clients.
+func applyOrderFilters(dbQuery *gorm.DB, c *gin.Context) (*gorm.DB, error) {
+	if status := c.Query("status"); status != "" {
+		switch status {
+		case models.OrderStatusPending, models.OrderStatusPaid, models.OrderStatusShipped, models.OrderStatusCancelled:
+		default:
+			return nil, fmt.Errorf("invalid status %q", status)
+		}
+		dbQuery = dbQuery.Where("status = ?", status)
+	}
+
+	if source := c.Query("source"); source != "" {
+		if !models.IsValidOrderSource(source) {
+			return nil, fmt.Errorf("invalid source %q", source)
+		}
+		dbQuery = dbQuery.Where("source = ?", source)
+	}
+
+	var from, to time.Time
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, errors.New("invalid from date, expected YYYY-MM-DD")
+		}
+		from = t
+		dbQuery = dbQuery.Where("created_at >= ?", from)
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, errors.New("invalid to date, expected YYYY-MM-DD")
+		}
+		to = t
+		dbQuery = dbQuery.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, errors.New("from must not be after to")
+	}
+
+	return dbQuery, nil
+}
+
+// applyOrderIncludes preloads the relations named by the comma-separated include query param.
+// Items are preloaded when the param is absent and nothing is preloaded when it is empty.
+func applyOrderIncludes(dbQuery *gorm.DB, c *gin.Context) (*gorm.DB, error) {
+	include, ok := c.GetQuery("include")
+	if !ok {
+		return dbQuery.Preload("Items"), nil
+	}
+
+	for _, name := range strings.Split(include, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		relation, ok := orderIncludes[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid include %q, must be one of items, items.product", name)
+		}
+		dbQuery = dbQuery.Preload(relation)
+	}
+
+	return dbQuery, nil
+}
+
+// nextOrderStatuses returns the statuses an order may move to from a status, never nil
+func nextOrderStatuses(from string) []string {
+	return append([]string{}, orderStatusTransitions[from]...)
+}
+
+// canTransition reports whether an order may move from one status to another
+func canTransition(from, to string) bool {
+	for _, next := range orderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
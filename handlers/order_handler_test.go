@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listOrderStatuses calls ListOrderStatuses with a query string and returns the response
+func listOrderStatuses(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/orders/statuses"+query, nil)
+	(&OrderHandler{}).ListOrderStatuses(c)
+	return w
+}
+
+func TestListOrderStatusesMatchesEnforcement(t *testing.T) {
+	w := listOrderStatuses(t, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var body struct {
+		Statuses    []string            `json:"statuses"`
+		Transitions map[string][]string `json:"transitions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid body %s: %v", w.Body.String(), err)
+	}
+	if !reflect.DeepEqual(body.Statuses, orderStatuses) {
+		t.Errorf("statuses = %v, want %v", body.Statuses, orderStatuses)
+	}
+
+	// Every listed transition is allowed by canTransition, and every allowed one is listed
+	for _, from := range orderStatuses {
+		next, ok := body.Transitions[from]
+		if !ok || next == nil {
+			t.Errorf("transitions[%s] = %v, want a list", from, next)
+		}
+		for _, to := range orderStatuses {
+			if listed := slices.Contains(next, to); listed != canTransition(from, to) {
+				t.Errorf("%s -> %s listed = %v, canTransition = %v", from, to, listed, canTransition(from, to))
+			}
+		}
+	}
+
+	// The transition table only mentions known statuses
+	for from, targets := range orderStatusTransitions {
+		if !slices.Contains(orderStatuses, from) {
+			t.Errorf("transition from unknown status %q", from)
+		}
+		for _, to := range targets {
+			if !slices.Contains(orderStatuses, to) {
+				t.Errorf("transition from %s to unknown status %q", from, to)
+			}
+		}
+	}
+}
+
+func TestListOrderStatusesCurrent(t *testing.T) {
+	for _, status := range orderStatuses {
+		t.Run(status, func(t *testing.T) {
+			w := listOrderStatuses(t, "?current="+status)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			var body struct {
+				Status string   `json:"status"`
+				Next   []string `json:"next"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid body %s: %v", w.Body.String(), err)
+			}
+			if body.Status != status || body.Next == nil || !reflect.DeepEqual(body.Next, nextOrderStatuses(status)) {
+				t.Errorf("body = %+v, want %s with next %v", body, status, nextOrderStatuses(status))
+			}
+		})
+	}
+
+	if w := listOrderStatuses(t, "?current=lost"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown current status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUpdateOrderStatusAcceptsListedStatuses(t *testing.T) {
+	field, _ := reflect.TypeOf(UpdateOrderStatusRequest{}).FieldByName("Status")
+	want := "oneof=" + strings.Join(orderStatuses, " ")
+	if tag := field.Tag.Get("binding"); !strings.Contains(tag, want) {
+		t.Errorf("UpdateOrderStatusRequest.Status binding = %q, want it to accept %s", tag, want)
+	}
+}
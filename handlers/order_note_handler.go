@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// OrderNoteHandler handles order note endpoints
+type OrderNoteHandler struct {
+	db *gorm.DB
+}
+
+// NewOrderNoteHandler creates a new order note handler
+func NewOrderNoteHandler(db *gorm.DB) *OrderNoteHandler {
+	return &OrderNoteHandler{
+		db: db,
+	}
+}
+
+// CreateOrderNoteRequest represents order note input
+type CreateOrderNoteRequest struct {
+	Body       string `json:"body" binding:"required,max=5000"`
+	IsInternal *bool  `json:"is_internal"`
+}
+
+// CreateOrderNote adds a note to an order (admin only).
+// Notes are internal unless is_internal is explicitly false.
+func (h *OrderNoteHandler) CreateOrderNote(c *gin.Context) {
+	authorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	var req CreateOrderNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+
+	var order models.Order
+	if err := db.Select("id").First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get order",
+		})
+		return
+	}
+
+	note := &models.OrderNote{
+		OrderID:    orderID,
+		AuthorID:   authorID,
+		Body:       req.Body,
+		IsInternal: req.IsInternal == nil || *req.IsInternal,
+	}
+	// Select all fields so an explicit false isn't replaced by the column default
+	if err := db.Select("*").Create(note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to create order note",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// ListOrderNotes lists an order's notes, newest first (admin only)
+func (h *OrderNoteHandler) ListOrderNotes(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	page, size, err := parsePagination(c, defaultPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	dbQuery := h.db.WithContext(c.Request.Context()).Model(&models.OrderNote{}).Where("order_id = ?", orderID)
+
+	if v := c.Query("is_internal"); v != "" {
+		isInternal, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "is_internal must be true or false",
+			})
+			return
+		}
+		dbQuery = dbQuery.Where("is_internal = ?", isInternal)
+	}
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count order notes",
+		})
+		return
+	}
+
+	var notes []models.OrderNote
+	offset := (page - 1) * size
+	if err := dbQuery.Preload("Author").Order("created_at DESC").Limit(size).Offset(offset).Find(&notes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list order notes",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notes": notes,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// receiptVersion is the version of the receipt schema. Fields may be added within a version;
+// renaming or removing one needs a new version.
+const receiptVersion = 1
+
+// Receipt is the stable, versioned receipt of an order, meant for printing and archiving
+type Receipt struct {
+	Version         int            `json:"version"`
+	OrderID         uuid.UUID      `json:"order_id"`
+	OrderNumber     string         `json:"order_number"`
+	Status          string         `json:"status"`
+	PlacedAt        time.Time      `json:"placed_at"`
+	Currency        string         `json:"currency"`
+	Items           []ReceiptItem  `json:"items"`
+	Totals          ReceiptTotals  `json:"totals"`
+	PaymentMethod   *string        `json:"payment_method"`
+	ShippingAddress models.JSONMap `json:"shipping_address"`
+	IsGift          bool           `json:"is_gift"`
+	GiftMessage     string         `json:"gift_message"`
+}
+
+// ReceiptItem is a receipt line. Prices are the ones charged; quantity is in grams for
+// products sold by weight, whose unit price is per kilogram.
+type ReceiptItem struct {
+	ProductID      uuid.UUID `json:"product_id"`
+	SKU            string    `json:"sku"`
+	Name           string    `json:"name"`
+	UnitType       string    `json:"unit_type"`
+	Quantity       int       `json:"quantity"`
+	UnitPriceCents int       `json:"unit_price_cents"`
+	LineTotalCents int64     `json:"line_total_cents"`
+}
+
+// ReceiptTotals breaks the order total down. TotalCents is the subtotal less the discount plus
+// the signed adjustments; refunds are listed but not taken off the total.
+type ReceiptTotals struct {
+	SubtotalCents    int64 `json:"subtotal_cents"`
+	DiscountCents    int64 `json:"discount_cents"`
+	AdjustmentsCents int64 `json:"adjustments_cents"`
+	TotalCents       int64 `json:"total_cents"`
+	RefundedCents    int64 `json:"refunded_cents"`
+}
+
+// GetOrderReceipt returns the receipt of one of the current user's orders.
+// Item names and SKUs are the products' current ones, as order items don't keep a copy.
+func (h *OrderHandler) GetOrderReceipt(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	order, err := findOrder(db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Items.Product"), id, &userID)
+	if err != nil {
+		// Another user's order is reported as missing, as in GetOrder
+		if errors.Is(err, errOrderNotFound) || errors.Is(err, errOrderForbidden) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get order",
+		})
+		return
+	}
+
+	var sums receiptSums
+	if err := db.Raw(`SELECT
+		(SELECT COALESCE(SUM(amount_cents), 0) FROM order_discounts WHERE order_id = @id) AS discount_cents,
+		(SELECT COALESCE(SUM(amount_cents), 0) FROM order_adjustments WHERE order_id = @id) AS adjustments_cents,
+		(SELECT COALESCE(SUM(amount_cents), 0) FROM refunds WHERE order_id = @id) AS refunded_cents`,
+		map[string]interface{}{"id": order.ID}).Scan(&sums).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get order",
+		})
+		return
+	}
+
+	receipt := buildReceipt(order, sums)
+	c.JSON(http.StatusOK, receipt)
+}
+
+// receiptSums are the totals of an order's discounts, adjustments and refunds
+type receiptSums struct {
+	DiscountCents    int64
+	AdjustmentsCents int64
+	RefundedCents    int64
+}
+
+// buildReceipt builds the receipt of an order with its items and their products loaded
+func buildReceipt(order *models.Order, sums receiptSums) Receipt {
+	receipt := Receipt{
+		Version:         receiptVersion,
+		OrderID:         order.ID,
+		OrderNumber:     order.OrderNumber,
+		Status:          order.Status,
+		PlacedAt:        order.CreatedAt,
+		Currency:        order.Currency,
+		Items:           make([]ReceiptItem, 0, len(order.Items)),
+		ShippingAddress: order.ShippingAddress,
+		IsGift:          order.IsGift,
+		GiftMessage:     order.GiftMessage,
+		Totals: ReceiptTotals{
+			DiscountCents:    sums.DiscountCents,
+			AdjustmentsCents: sums.AdjustmentsCents,
+			TotalCents:       int64(order.TotalCents),
+			RefundedCents:    sums.RefundedCents,
+		},
+	}
+	if method, ok := order.PaymentInfo["method"].(string); ok {
+		receipt.PaymentMethod = &method
+	}
+	for _, item := range order.Items {
+		line := ReceiptItem{
+			ProductID:      item.ProductID,
+			UnitType:       item.UnitType,
+			Quantity:       item.Quantity,
+			UnitPriceCents: item.PriceCents,
+			LineTotalCents: item.LineTotalCents(item.Quantity),
+		}
+		if item.Product != nil {
+			line.SKU = item.Product.SKU
+			line.Name = item.Product.Name
+		}
+		receipt.Totals.SubtotalCents += line.LineTotalCents
+		receipt.Items = append(receipt.Items, line)
+	}
+	return receipt
+}
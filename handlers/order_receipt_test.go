@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files with the current output")
+
+func TestBuildReceiptGolden(t *testing.T) {
+	placed := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	order := &models.Order{
+		ID:          uuid.MustParse("2d9b6f1e-7c3a-4e85-b1d2-9f4a6c8e0b37"),
+		OrderNumber: "ORD-2026-000123",
+		Status:      models.OrderStatusPaid,
+		TotalCents:  4097 - 410 + 500,
+		Currency:    "USD",
+		ShippingAddress: models.JSONMap{
+			"line1":   "1 Main St",
+			"city":    "Springfield",
+			"country": "US",
+		},
+		PaymentInfo: models.JSONMap{"method": "card", "last4": "4242"},
+		IsGift:      true,
+		GiftMessage: "Happy birthday",
+		Items: []models.OrderItem{
+			{
+				ProductID:  uuid.MustParse("6f1c2a9e-4b57-4c1f-9d3e-2a8f1b7c5d40"),
+				Product:    &models.Product{SKU: "MUG-1", Name: "Mug"},
+				Quantity:   2,
+				PriceCents: 1299,
+				UnitType:   models.UnitTypeEach,
+			},
+			{
+				// 750 g at 19.99 per kilogram
+				ProductID:  uuid.MustParse("0b6f3c1e-8d2a-4f57-9c41-7e2d5a9b3f10"),
+				Product:    &models.Product{SKU: "COFFEE-KG", Name: "Coffee beans"},
+				Quantity:   750,
+				PriceCents: 1999,
+				UnitType:   models.UnitTypeWeight,
+			},
+			{
+				// A line whose product is gone has no name or SKU
+				ProductID:  uuid.MustParse("9a4e7c2b-1f3d-4b68-8e5a-6c0d2f9b7a31"),
+				Quantity:   1,
+				PriceCents: 0,
+				UnitType:   models.UnitTypeEach,
+			},
+		},
+		CreatedAt: placed,
+	}
+	sums := receiptSums{DiscountCents: 410, AdjustmentsCents: 500, RefundedCents: 1299}
+
+	got, err := json.MarshalIndent(buildReceipt(order, sums), "", "  ")
+	if err != nil {
+		t.Fatalf("marshal receipt: %v", err)
+	}
+	got = append(got, '\n')
+
+	golden := filepath.Join("testdata", "receipt_v1.golden.json")
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("receipt changed; a field rename or removal needs a new receipt version.\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuildReceiptWithoutPayment(t *testing.T) {
+	receipt := buildReceipt(&models.Order{Currency: "USD"}, receiptSums{})
+
+	if receipt.Version != receiptVersion || receipt.PaymentMethod != nil {
+		t.Errorf("receipt = %+v, want version %d without a payment method", receipt, receiptVersion)
+	}
+	data, err := json.Marshal(receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if string(fields["items"]) != "[]" || string(fields["payment_method"]) != "null" {
+		t.Errorf("items = %s, payment_method = %s; want [] and null", fields["items"], fields["payment_method"])
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// maxOrderWaitSeconds caps how long a long-polling order request is held
+const maxOrderWaitSeconds = 30
+
+// orderWaitRecheckInterval is how often a long poll re-reads the order, which catches
+// status changes made by other server instances
+const orderWaitRecheckInterval = 2 * time.Second
+
+// orderStatusWatch is the channel closed on an order's next status change and the number of requests waiting on it
+type orderStatusWatch struct {
+	changed chan struct{}
+	waiters int
+}
+
+// orderStatusWatcher lets long-polling requests wait for status changes made by this server instance
+type orderStatusWatcher struct {
+	mu      sync.Mutex
+	watches map[uuid.UUID]*orderStatusWatch
+}
+
+// newOrderStatusWatcher creates a new order status watcher
+func newOrderStatusWatcher() *orderStatusWatcher {
+	return &orderStatusWatcher{
+		watches: make(map[uuid.UUID]*orderStatusWatch),
+	}
+}
+
+// watch returns a channel that is closed on the order's next status change.
+// The returned release func must be called once the caller stops waiting.
+func (w *orderStatusWatcher) watch(orderID uuid.UUID) (<-chan struct{}, func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watch, ok := w.watches[orderID]
+	if !ok {
+		watch = &orderStatusWatch{changed: make(chan struct{})}
+		w.watches[orderID] = watch
+	}
+	watch.waiters++
+
+	return watch.changed, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		watch.waiters--
+		if watch.waiters == 0 && w.watches[orderID] == watch {
+			delete(w.watches, orderID)
+		}
+	}
+}
+
+// notify wakes every request waiting on the order. Call it after the status change commits.
+func (w *orderStatusWatcher) notify(orderID uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if watch, ok := w.watches[orderID]; ok {
+		close(watch.changed)
+		delete(w.watches, orderID)
+	}
+}
+
+// waitForStatusChange reloads order through orderQuery once its status changes, or leaves it as is
+// when wait elapses or ctx is done. changed must come from watch, taken before order was loaded.
+func waitForStatusChange(ctx context.Context, orderQuery *gorm.DB, order *models.Order, changed <-chan struct{}, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	recheck := time.NewTicker(orderWaitRecheckInterval)
+	defer recheck.Stop()
+
+	for {
+		select {
+		case <-changed:
+			var current models.Order
+			if err := orderQuery.First(&current, order.ID).Error; err != nil {
+				return err
+			}
+			*order = current
+			return nil
+		case <-recheck.C:
+			var current models.Order
+			if err := orderQuery.First(&current, order.ID).Error; err != nil {
+				return err
+			}
+			if current.Status != order.Status {
+				*order = current
+				return nil
+			}
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// orderStore stands in for the orders table: queries through its db load the stored order
+type orderStore struct {
+	mu    sync.Mutex
+	order models.Order
+	reads int
+}
+
+func newOrderStore(t *testing.T, order models.Order) (*orderStore, *gorm.DB) {
+	t.Helper()
+	s := &orderStore{order: order}
+	db := dryRunDB(t).DB
+	err := db.Callback().Query().After("gorm:query").Register("test:load_order", func(tx *gorm.DB) {
+		if dest, ok := tx.Statement.Dest.(*models.Order); ok {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			*dest = s.order
+			s.reads++
+		}
+	})
+	if err != nil {
+		t.Fatalf("register query callback: %v", err)
+	}
+	return s, db
+}
+
+func (s *orderStore) setStatus(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order.Status = status
+}
+
+func TestOrderStatusWatcherNotify(t *testing.T) {
+	w := newOrderStatusWatcher()
+	orderID := uuid.New()
+	other := uuid.New()
+
+	changed, release := w.watch(orderID)
+	defer release()
+	otherChanged, releaseOther := w.watch(other)
+	defer releaseOther()
+
+	w.notify(orderID)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("watch channel not closed after notify")
+	}
+	select {
+	case <-otherChanged:
+		t.Fatal("notify woke a watcher of another order")
+	default:
+	}
+
+	// The next watch waits for the next change, not the one already delivered
+	next, releaseNext := w.watch(orderID)
+	defer releaseNext()
+	select {
+	case <-next:
+		t.Fatal("new watch channel already closed")
+	default:
+	}
+}
+
+func TestOrderStatusWatcherRelease(t *testing.T) {
+	w := newOrderStatusWatcher()
+	orderID := uuid.New()
+
+	_, releaseFirst := w.watch(orderID)
+	_, releaseSecond := w.watch(orderID)
+	if got := w.watches[orderID].waiters; got != 2 {
+		t.Fatalf("waiters = %d, want 2", got)
+	}
+
+	releaseFirst()
+	if watch, ok := w.watches[orderID]; !ok || watch.waiters != 1 {
+		t.Fatalf("after one release watch = %+v, want 1 waiter left", watch)
+	}
+	releaseSecond()
+	if _, ok := w.watches[orderID]; ok {
+		t.Error("watch kept after its last waiter released it")
+	}
+
+	// Releasing after a notify must not remove the watch of a later waiter
+	_, releaseNotified := w.watch(orderID)
+	w.notify(orderID)
+	_, releaseLater := w.watch(orderID)
+	releaseNotified()
+	if watch, ok := w.watches[orderID]; !ok || watch.waiters != 1 {
+		t.Errorf("later watch = %+v, want it kept with 1 waiter", watch)
+	}
+	releaseLater()
+	if len(w.watches) != 0 {
+		t.Errorf("watches = %v, want none left", w.watches)
+	}
+}
+
+func TestWaitForStatusChangeReturnsOnChange(t *testing.T) {
+	order := models.Order{ID: uuid.New(), Status: models.OrderStatusPending}
+	orders, db := newOrderStore(t, order)
+	w := newOrderStatusWatcher()
+	changed, release := w.watch(order.ID)
+	defer release()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		orders.setStatus(models.OrderStatusShipped)
+		w.notify(order.ID)
+	}()
+
+	start := time.Now()
+	if err := waitForStatusChange(context.Background(), db, &order, changed, 10*time.Second); err != nil {
+		t.Fatalf("waitForStatusChange() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waitForStatusChange() took %v, want it to return right after the change", elapsed)
+	}
+	if order.Status != models.OrderStatusShipped {
+		t.Errorf("status = %q, want the reloaded %q", order.Status, models.OrderStatusShipped)
+	}
+}
+
+func TestWaitForStatusChangeTimesOut(t *testing.T) {
+	order := models.Order{ID: uuid.New(), Status: models.OrderStatusPending}
+	orders, db := newOrderStore(t, order)
+	w := newOrderStatusWatcher()
+	changed, release := w.watch(order.ID)
+	defer release()
+
+	start := time.Now()
+	if err := waitForStatusChange(context.Background(), db, &order, changed, 50*time.Millisecond); err != nil {
+		t.Fatalf("waitForStatusChange() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("waitForStatusChange() returned after %v, before the wait elapsed", elapsed)
+	}
+	if order.Status != models.OrderStatusPending {
+		t.Errorf("status = %q, want the current %q", order.Status, models.OrderStatusPending)
+	}
+	if orders.reads != 0 {
+		t.Errorf("order read %d times, want none before the first recheck", orders.reads)
+	}
+}
+
+func TestWaitForStatusChangeStopsWithContext(t *testing.T) {
+	order := models.Order{ID: uuid.New(), Status: models.OrderStatusPending}
+	_, db := newOrderStore(t, order)
+	w := newOrderStatusWatcher()
+	changed, release := w.watch(order.ID)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := waitForStatusChange(ctx, db, &order, changed, 10*time.Second); err != nil {
+		t.Fatalf("waitForStatusChange() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waitForStatusChange() took %v after the request was cancelled", elapsed)
+	}
+	if order.Status != models.OrderStatusPending {
+		t.Errorf("status = %q, want the current %q", order.Status, models.OrderStatusPending)
+	}
+}
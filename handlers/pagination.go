@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pagination defaults shared by all list endpoints.
+// Products and orders may be configured with their own default size.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	maxPage         = 1000000 // keeps the row offset, (page-1)*size, far from overflowing
+)
+
+var (
+	// errInvalidPagination is returned when page or size isn't a positive integer
+	errInvalidPagination = errors.New("page and size must be positive integers")
+	// errPageTooLarge is returned when page is beyond maxPage
+	errPageTooLarge = errors.New("page must not exceed 1000000")
+)
+
+// TotalCountHeader carries the total number of results of a paginated list
+const TotalCountHeader = "X-Total-Count"
+
+// setTotalCount exposes a list's total in the X-Total-Count header alongside the body envelope
+func setTotalCount(c *gin.Context, total int64) {
+	c.Header(TotalCountHeader, strconv.FormatInt(total, 10))
+}
+
+// parsePagination reads the page and size query parameters.
+// Missing values fall back to the first page of defaultSize, size is capped at maxPageSize
+// and pages past maxPage are rejected.
+func parsePagination(c *gin.Context, defaultSize int) (page, size int, err error) {
+	page, err = strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, errInvalidPagination
+	}
+	if page > maxPage {
+		return 0, 0, errPageTooLarge
+	}
+	size, err = strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultSize)))
+	if err != nil || size < 1 {
+		return 0, 0, errInvalidPagination
+	}
+	return page, min(size, maxPageSize), nil
+}
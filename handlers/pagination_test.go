@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		defaultSize int
+		wantPage    int
+		wantSize    int
+		wantErr     error
+	}{
+		{"defaults", "", defaultPageSize, 1, defaultPageSize, nil},
+		{"configured default", "?page=2", 50, 2, 50, nil},
+		{"explicit values", "?page=3&size=50", defaultPageSize, 3, 50, nil},
+		{"size capped", "?size=500", defaultPageSize, 1, maxPageSize, nil},
+		{"last allowed page", "?page=1000000&size=100", defaultPageSize, maxPage, 100, nil},
+		{"page past the cap", "?page=1000001", defaultPageSize, 0, 0, errPageTooLarge},
+		{"page that would overflow the offset", "?page=9223372036854775807&size=100", defaultPageSize, 0, 0, errPageTooLarge},
+		{"page out of int range", "?page=99999999999999999999", defaultPageSize, 0, 0, errInvalidPagination},
+		{"zero page", "?page=0", defaultPageSize, 0, 0, errInvalidPagination},
+		{"negative size", "?size=-5", defaultPageSize, 0, 0, errInvalidPagination},
+		{"page not a number", "?page=abc", defaultPageSize, 0, 0, errInvalidPagination},
+		{"empty size", "?size=", defaultPageSize, 0, 0, errInvalidPagination},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+
+			page, size, err := parsePagination(c, tt.defaultSize)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parsePagination() error = %v, want %v", err, tt.wantErr)
+			}
+			if page != tt.wantPage || size != tt.wantSize {
+				t.Errorf("parsePagination() = %d, %d; want %d, %d", page, size, tt.wantPage, tt.wantSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// errTierPriceNotLower is returned when a fixed tier price isn't a discount
+var errTierPriceNotLower = errors.New("tier price_cents must be lower than the product's price")
+
+// PriceTierRequest is one volume discount tier.
+// Exactly one of price_cents and percent_off must be set.
+type PriceTierRequest struct {
+	MinQuantity int  `json:"min_quantity" binding:"required,min=2"`
+	PriceCents  *int `json:"price_cents" binding:"omitempty,min=0"`
+	PercentOff  *int `json:"percent_off" binding:"omitempty,min=1,max=99"`
+}
+
+// SetPriceTiersRequest replaces a product's volume discount tiers; an empty list removes them
+type SetPriceTiersRequest struct {
+	Tiers []PriceTierRequest `json:"tiers" binding:"required,max=10,dive"`
+}
+
+// SetPriceTiers replaces a product's volume discount tiers (admin only).
+// Carts and checkouts use the lowest unit price among the tiers a quantity qualifies for.
+func (h *ProductHandler) SetPriceTiers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	var req SetPriceTiersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	seen := make(map[int]bool, len(req.Tiers))
+	for _, tier := range req.Tiers {
+		if (tier.PriceCents == nil) == (tier.PercentOff == nil) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "each tier needs exactly one of price_cents and percent_off",
+			})
+			return
+		}
+		if seen[tier.MinQuantity] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "tiers must not repeat a min_quantity",
+			})
+			return
+		}
+		seen[tier.MinQuantity] = true
+	}
+
+	var product models.Product
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		if err := tx.First(&product, id).Error; err != nil {
+			return err
+		}
+		for _, tier := range req.Tiers {
+			if tier.PriceCents != nil && *tier.PriceCents >= product.PriceCents {
+				return errTierPriceNotLower
+			}
+		}
+
+		if err := tx.Where("product_id = ?", id).Delete(&models.PriceTier{}).Error; err != nil {
+			return err
+		}
+		product.PriceTiers = make([]models.PriceTier, 0, len(req.Tiers))
+		for _, tier := range req.Tiers {
+			product.PriceTiers = append(product.PriceTiers, models.PriceTier{
+				ProductID:   id,
+				MinQuantity: tier.MinQuantity,
+				PriceCents:  tier.PriceCents,
+				PercentOff:  tier.PercentOff,
+			})
+		}
+		if len(product.PriceTiers) == 0 {
+			return nil
+		}
+		return tx.Create(&product.PriceTiers).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+		case errors.Is(err, errTierPriceNotLower):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to set price tiers",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
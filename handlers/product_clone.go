@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// CloneProductRequest represents product clone input; the name defaults to the original's
+type CloneProductRequest struct {
+	SKU  string `json:"sku" binding:"required,max=64"`
+	Name string `json:"name" binding:"omitempty,max=200"`
+}
+
+// CloneProduct copies a product into a new draft product with its own SKU (admin only).
+// Details, images, tags, price tiers and bundle components are copied; stock and reviews are not.
+// A cloned bundle's stock follows its components as usual.
+func (h *ProductHandler) CloneProduct(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	var req CloneProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	var clone *models.Product
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		var original models.Product
+		if err := tx.Preload("BundleItems").Preload("PriceTiers").First(&original, id).Error; err != nil {
+			return err
+		}
+
+		clone = &models.Product{
+			SKU:         req.SKU,
+			Name:        original.Name,
+			Description: original.Description,
+			PriceCents:  original.PriceCents,
+			Currency:    original.Currency,
+			UnitType:    original.UnitType,
+			WeightGrams: original.WeightGrams,
+			LengthMm:    original.LengthMm,
+			WidthMm:     original.WidthMm,
+			HeightMm:    original.HeightMm,
+			Images:      append(models.JSONStringSlice{}, original.Images...),
+			Tags:        append(models.JSONStringSlice{}, original.Tags...),
+			IsBundle:    original.IsBundle,
+			Status:      models.ProductStatusDraft,
+		}
+		if req.Name != "" {
+			clone.Name = req.Name
+		}
+		if err := tx.Create(clone).Error; err != nil {
+			return err
+		}
+
+		if len(original.PriceTiers) > 0 {
+			clone.PriceTiers = make([]models.PriceTier, 0, len(original.PriceTiers))
+			for _, tier := range original.PriceTiers {
+				clone.PriceTiers = append(clone.PriceTiers, models.PriceTier{
+					ProductID:   clone.ID,
+					MinQuantity: tier.MinQuantity,
+					PriceCents:  tier.PriceCents,
+					PercentOff:  tier.PercentOff,
+				})
+			}
+			if err := tx.Create(&clone.PriceTiers).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(original.BundleItems) == 0 {
+			return nil
+		}
+		items := make([]BundleItemRequest, 0, len(original.BundleItems))
+		for _, item := range original.BundleItems {
+			items = append(items, BundleItemRequest{
+				ProductID: item.ComponentID,
+				Quantity:  item.Quantity,
+			})
+		}
+		return createBundleItems(tx, clone, items)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+		case isUniqueViolation(err):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "a product with this SKU already exists",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to clone product",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, clone)
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// productExportBatchSize caps the products loaded into memory at once during an export
+const productExportBatchSize = 500
+
+// productExportColumns is the header row of CSV exports
+var productExportColumns = []string{
+	"id", "sku", "name", "description", "price_cents", "currency", "stock",
+	"unit_type", "weight_grams", "length_mm", "width_mm", "height_mm", "status", "is_bundle", "images", "tags",
+	"bundle_items", "price_tiers", "created_at", "updated_at",
+}
+
+// productExporter writes products in one export format
+type productExporter interface {
+	begin() error
+	write(products []models.Product) error
+	end() error
+}
+
+// ExportProducts streams the whole catalog, in every status, as a JSON or CSV download (admin only).
+// Products are read in batches so large catalogs aren't loaded into memory at once.
+// Once streaming has started a failure can't change the status code, so it is logged and the download is cut short.
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+
+	var exporter productExporter
+	switch format {
+	case "json":
+		exporter = &jsonProductExporter{w: c.Writer}
+		c.Header("Content-Type", "application/json")
+	case "csv":
+		exporter = &csvProductExporter{w: csv.NewWriter(c.Writer)}
+		c.Header("Content-Type", "text/csv")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "format must be json or csv",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("products-%s.%s", time.Now().UTC().Format("20060102"), format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	if err := exporter.begin(); err != nil {
+		log.Printf("Failed to export products: %v", err)
+		return
+	}
+
+	var products []models.Product
+	result := h.db.WithContext(c.Request.Context()).
+		Preload("BundleItems").
+		Preload("PriceTiers").
+		FindInBatches(&products, productExportBatchSize, func(tx *gorm.DB, batch int) error {
+			if err := exporter.write(products); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+			return nil
+		})
+	if result.Error != nil {
+		log.Printf("Failed to export products: %v", result.Error)
+		return
+	}
+
+	if err := exporter.end(); err != nil {
+		log.Printf("Failed to export products: %v", err)
+	}
+}
+
+// jsonProductExporter writes products as a single JSON array
+type jsonProductExporter struct {
+	w       http.ResponseWriter
+	written int
+}
+
+func (e *jsonProductExporter) begin() error {
+	_, err := e.w.Write([]byte("["))
+	return err
+}
+
+func (e *jsonProductExporter) write(products []models.Product) error {
+	for i := range products {
+		if e.written > 0 {
+			if _, err := e.w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		out, err := json.Marshal(&products[i])
+		if err != nil {
+			return err
+		}
+		if _, err := e.w.Write(out); err != nil {
+			return err
+		}
+		e.written++
+	}
+	return nil
+}
+
+func (e *jsonProductExporter) end() error {
+	_, err := e.w.Write([]byte("]\n"))
+	return err
+}
+
+// csvProductExporter writes products as CSV rows under a header.
+// Images, tags, bundle items and price tiers are written as JSON arrays so values containing separators survive the round trip.
+type csvProductExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvProductExporter) begin() error {
+	return e.w.Write(productExportColumns)
+}
+
+func (e *csvProductExporter) write(products []models.Product) error {
+	for _, product := range products {
+		images, err := json.Marshal(product.Images)
+		if err != nil {
+			return err
+		}
+		tags, err := json.Marshal(product.Tags)
+		if err != nil {
+			return err
+		}
+		if product.BundleItems == nil {
+			product.BundleItems = []models.BundleItem{}
+		}
+		bundleItems, err := json.Marshal(product.BundleItems)
+		if err != nil {
+			return err
+		}
+		if product.PriceTiers == nil {
+			product.PriceTiers = []models.PriceTier{}
+		}
+		priceTiers, err := json.Marshal(product.PriceTiers)
+		if err != nil {
+			return err
+		}
+		if err := e.w.Write([]string{
+			product.ID.String(),
+			product.SKU,
+			product.Name,
+			product.Description,
+			strconv.Itoa(product.PriceCents),
+			product.Currency,
+			strconv.Itoa(product.Stock),
+			product.UnitType,
+			strconv.Itoa(product.WeightGrams),
+			strconv.Itoa(product.LengthMm),
+			strconv.Itoa(product.WidthMm),
+			strconv.Itoa(product.HeightMm),
+			product.Status,
+			strconv.FormatBool(product.IsBundle),
+			string(images),
+			string(tags),
+			string(bundleItems),
+			string(priceTiers),
+			product.CreatedAt.UTC().Format(time.RFC3339),
+			product.UpdatedAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvProductExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
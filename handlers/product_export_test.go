@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+)
+
+// exportTestProducts returns a plain product with images that need quoting and a bundle of it
+func exportTestProducts() []models.Product {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mug := models.Product{
+		ID:          uuid.MustParse("6f1c2a9e-4b57-4c1f-9d3e-2a8f1b7c5d40"),
+		SKU:         "MUG-1",
+		Name:        `Mug, "large"`,
+		Description: "Holds coffee\nor tea",
+		PriceCents:  1299,
+		Currency:    "USD",
+		Stock:       8,
+		UnitType:    models.UnitTypeEach,
+		Status:      models.ProductStatusPublished,
+		Images:      models.JSONStringSlice{"https://cdn.example.com/mug,front.jpg"},
+		PriceTiers: []models.PriceTier{
+			{ID: uuid.MustParse("3c8d1e6f-2a4b-4d9e-b7f1-5e0a9c3d2b84"), MinQuantity: 6, PercentOff: intPtr(10), CreatedAt: created},
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+	set := models.Product{
+		ID:         uuid.MustParse("0b6f3c1e-8d2a-4f57-9c41-7e2d5a9b3f10"),
+		SKU:        "MUG-SET",
+		Name:       "Mug set",
+		PriceCents: 2199,
+		Currency:   "USD",
+		Stock:      4,
+		UnitType:   models.UnitTypeEach,
+		Status:     models.ProductStatusDraft,
+		IsBundle:   true,
+		BundleItems: []models.BundleItem{
+			{ID: uuid.MustParse("9a4e7c2b-1f3d-4b68-8e5a-6c0d2f9b7a31"), ComponentID: mug.ID, Quantity: 2, CreatedAt: created},
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+	mug.PriceTiers[0].ProductID = mug.ID
+	set.BundleItems[0].BundleID = set.ID
+	return []models.Product{mug, set}
+}
+
+// runExport writes products through an exporter one product per batch
+func runExport(t *testing.T, exporter productExporter, products []models.Product) {
+	t.Helper()
+	if err := exporter.begin(); err != nil {
+		t.Fatalf("begin() error = %v", err)
+	}
+	for i := range products {
+		if err := exporter.write(products[i : i+1]); err != nil {
+			t.Fatalf("write() error = %v", err)
+		}
+	}
+	if err := exporter.end(); err != nil {
+		t.Fatalf("end() error = %v", err)
+	}
+}
+
+func TestJSONProductExport(t *testing.T) {
+	products := exportTestProducts()
+	w := httptest.NewRecorder()
+	runExport(t, &jsonProductExporter{w: w}, products)
+
+	var got []models.Product
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("export is not a JSON array: %v\n%s", err, w.Body.String())
+	}
+	if len(got) != len(products) {
+		t.Fatalf("exported %d products, want %d", len(got), len(products))
+	}
+	for i, want := range products {
+		if got[i].ID != want.ID || got[i].SKU != want.SKU || got[i].Name != want.Name || got[i].PriceCents != want.PriceCents {
+			t.Errorf("product %d = %+v, want %+v", i, got[i], want)
+		}
+		if len(got[i].BundleItems) != len(want.BundleItems) || len(got[i].PriceTiers) != len(want.PriceTiers) {
+			t.Fatalf("product %d has %d bundle items and %d price tiers, want %d and %d",
+				i, len(got[i].BundleItems), len(got[i].PriceTiers), len(want.BundleItems), len(want.PriceTiers))
+		}
+	}
+	if tier := got[0].PriceTiers[0]; tier.MinQuantity != 6 || tier.PercentOff == nil || *tier.PercentOff != 10 {
+		t.Errorf("price tier = %+v, want 10%% off from 6", tier)
+	}
+	if item := got[1].BundleItems[0]; item.ComponentID != products[0].ID || item.Quantity != 2 {
+		t.Errorf("bundle item = %+v, want 2 of %s", item, products[0].ID)
+	}
+}
+
+func TestJSONProductExportEmpty(t *testing.T) {
+	w := httptest.NewRecorder()
+	runExport(t, &jsonProductExporter{w: w}, nil)
+
+	if got := strings.TrimSpace(w.Body.String()); got != "[]" {
+		t.Errorf("empty export = %q, want []", got)
+	}
+}
+
+func TestCSVProductExport(t *testing.T) {
+	products := exportTestProducts()
+	w := httptest.NewRecorder()
+	runExport(t, &csvProductExporter{w: csv.NewWriter(w)}, products)
+
+	rows, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v\n%s", err, w.Body.String())
+	}
+	if len(rows) != len(products)+1 {
+		t.Fatalf("exported %d rows, want a header and %d products", len(rows), len(products))
+	}
+	if strings.Join(rows[0], ",") != strings.Join(productExportColumns, ",") {
+		t.Errorf("header = %v, want %v", rows[0], productExportColumns)
+	}
+
+	column := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		column[name] = i
+	}
+	for i, want := range products {
+		row := rows[i+1]
+		if row[column["id"]] != want.ID.String() || row[column["name"]] != want.Name || row[column["description"]] != want.Description {
+			t.Errorf("row %d = %v, want %s %q %q", i+1, row, want.ID, want.Name, want.Description)
+		}
+		if row[column["price_cents"]] != strconv.Itoa(want.PriceCents) || row[column["is_bundle"]] != strconv.FormatBool(want.IsBundle) {
+			t.Errorf("row %d price_cents, is_bundle = %q, %q; want %d, %v",
+				i+1, row[column["price_cents"]], row[column["is_bundle"]], want.PriceCents, want.IsBundle)
+		}
+		if row[column["created_at"]] != "2026-03-01T12:00:00Z" {
+			t.Errorf("row %d created_at = %q, want RFC 3339 in UTC", i+1, row[column["created_at"]])
+		}
+
+		var images []string
+		if err := json.Unmarshal([]byte(row[column["images"]]), &images); err != nil {
+			t.Errorf("row %d images = %q, not a JSON array: %v", i+1, row[column["images"]], err)
+		}
+		var bundleItems []models.BundleItem
+		if err := json.Unmarshal([]byte(row[column["bundle_items"]]), &bundleItems); err != nil || bundleItems == nil {
+			t.Errorf("row %d bundle_items = %q, want a JSON array", i+1, row[column["bundle_items"]])
+		}
+		if len(bundleItems) != len(want.BundleItems) {
+			t.Errorf("row %d has %d bundle items, want %d", i+1, len(bundleItems), len(want.BundleItems))
+		}
+		var priceTiers []models.PriceTier
+		if err := json.Unmarshal([]byte(row[column["price_tiers"]]), &priceTiers); err != nil || priceTiers == nil {
+			t.Errorf("row %d price_tiers = %q, want a JSON array", i+1, row[column["price_tiers"]])
+		}
+		if len(priceTiers) != len(want.PriceTiers) {
+			t.Errorf("row %d has %d price tiers, want %d", i+1, len(priceTiers), len(want.PriceTiers))
+		}
+	}
+
+	var images []string
+	_ = json.Unmarshal([]byte(rows[1][column["images"]]), &images)
+	if len(images) != 1 || images[0] != products[0].Images[0] {
+		t.Errorf("images = %v, want %v", images, products[0].Images)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
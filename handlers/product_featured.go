@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+)
+
+// SetFeaturedRankRequest represents featured rank input. A null rank takes the product out of
+// the featured sort.
+type SetFeaturedRankRequest struct {
+	FeaturedRank *int `json:"featured_rank" binding:"omitempty,min=1"`
+}
+
+// SetFeaturedRank sets a product's position in the curated featured sort (admin only).
+// Lower ranks come first, ties are broken by newest; products without a rank come last.
+func (h *ProductHandler) SetFeaturedRank(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	var req SetFeaturedRankRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	result := db.Model(&models.Product{}).Where("id = ?", id).Update("featured_rank", req.FeaturedRank)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to set featured rank",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "product not found",
+		})
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sainudheenp/goecom/models"
+)
+
+// productFieldColumns maps the fields a product list can be narrowed to with the fields query
+// param to the columns they are built from. thumbnail is the first image.
+var productFieldColumns = map[string][]string{
+	"id":              {"id"},
+	"sku":             {"sku"},
+	"name":            {"name"},
+	"description":     {"description"},
+	"price_cents":     {"price_cents"},
+	"price_formatted": {"price_cents", "currency"},
+	"currency":        {"currency"},
+	"stock":           {"stock"},
+	"available_stock": {"stock"},
+	"unit_type":       {"unit_type"},
+	"weight_grams":    {"weight_grams"},
+	"length_mm":       {"length_mm"},
+	"width_mm":        {"width_mm"},
+	"height_mm":       {"height_mm"},
+	"images":          {"images"},
+	"thumbnail":       {"images"},
+	"tags":            {"tags"},
+	"is_bundle":       {"is_bundle"},
+	"status":          {"status"},
+	"featured_rank":   {"featured_rank"},
+	"flash_sale":      {"price_cents"},
+	"created_at":      {"created_at"},
+	"updated_at":      {"updated_at"},
+}
+
+// productFieldSet is a sparse fieldset for product lists
+type productFieldSet map[string]bool
+
+// parseProductFields parses the comma-separated fields query param.
+// It returns nil, meaning every field, when the param is empty.
+func parseProductFields(fields string) (productFieldSet, error) {
+	set := make(productFieldSet)
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := productFieldColumns[field]; !ok {
+			names := make([]string, 0, len(productFieldColumns))
+			for name := range productFieldColumns {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("fields must be a comma-separated list of %s", strings.Join(names, ", "))
+		}
+		set[field] = true
+	}
+	if len(set) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+// selectColumns returns the SELECT list for the fieldset. The id is always loaded since
+// flash sales are looked up by it.
+func (fs productFieldSet) selectColumns() string {
+	names := make([]string, 0, len(fs))
+	for field := range fs {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	seen := map[string]bool{"id": true}
+	columns := []string{"products.id"}
+	for _, field := range names {
+		for _, column := range productFieldColumns[field] {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, "products."+column)
+			}
+		}
+	}
+	if fs["available_stock"] {
+		columns = append(columns, availableStockExpr+" AS available_stock")
+	}
+	return strings.Join(columns, ", ")
+}
+
+// shape renders products with only the fields in the set
+func (fs productFieldSet) shape(products []models.Product) ([]map[string]interface{}, error) {
+	shaped := make([]map[string]interface{}, 0, len(products))
+	for i := range products {
+		data, err := json.Marshal(&products[i])
+		if err != nil {
+			return nil, err
+		}
+		var full map[string]interface{}
+		if err := json.Unmarshal(data, &full); err != nil {
+			return nil, err
+		}
+
+		out := make(map[string]interface{}, len(fs))
+		for field := range fs {
+			if field == "thumbnail" {
+				var thumbnail *string
+				if len(products[i].Images) > 0 {
+					thumbnail = &products[i].Images[0]
+				}
+				out[field] = thumbnail
+				continue
+			}
+			if value, ok := full[field]; ok {
+				out[field] = value
+			}
+		}
+		shaped = append(shaped, out)
+	}
+	return shaped, nil
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+)
+
+func TestParseProductFields(t *testing.T) {
+	tests := []struct {
+		fields  string
+		want    productFieldSet
+		wantErr bool
+	}{
+		{fields: "", want: nil},
+		{fields: " , ", want: nil},
+		{fields: "id,name", want: productFieldSet{"id": true, "name": true}},
+		{fields: " name , thumbnail,name", want: productFieldSet{"name": true, "thumbnail": true}},
+		{fields: "name,password", wantErr: true},
+		{fields: "Name", wantErr: true},
+		{fields: "products.name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fields, func(t *testing.T) {
+			got, err := parseProductFields(tt.fields)
+			if tt.wantErr {
+				if err == nil || !strings.HasPrefix(err.Error(), "fields must be a comma-separated list of ") {
+					t.Fatalf("parseProductFields(%q) error = %v, want the allowed fields", tt.fields, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseProductFields(%q) error = %v", tt.fields, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseProductFields(%q) = %v, want %v", tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductFieldSetSelectColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields productFieldSet
+		want   string
+	}{
+		{
+			name:   "id is always loaded",
+			fields: productFieldSet{"name": true},
+			want:   "products.id, products.name",
+		},
+		{
+			name:   "shared columns are loaded once",
+			fields: productFieldSet{"price_formatted": true, "price_cents": true, "flash_sale": true, "id": true},
+			want:   "products.id, products.price_cents, products.currency",
+		},
+		{
+			name:   "thumbnail loads the images",
+			fields: productFieldSet{"thumbnail": true},
+			want:   "products.id, products.images",
+		},
+		{
+			name:   "available stock",
+			fields: productFieldSet{"available_stock": true},
+			want:   "products.id, products.stock, " + availableStockExpr + " AS available_stock",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fields.selectColumns(); got != tt.want {
+				t.Errorf("selectColumns() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductFieldSetShape(t *testing.T) {
+	id := uuid.MustParse("6f1c2a9e-4b57-4c1f-9d3e-2a8f1b7c5d40")
+	products := []models.Product{
+		{
+			ID:         id,
+			Name:       "Mug",
+			PriceCents: 1299,
+			Images:     models.JSONStringSlice{"https://cdn.example.com/mug.jpg", "https://cdn.example.com/mug-2.jpg"},
+		},
+		{ID: id, Name: "Plate"},
+	}
+	fields := productFieldSet{"id": true, "name": true, "price_cents": true, "thumbnail": true, "flash_sale": true}
+
+	got, err := fields.shape(products)
+	if err != nil {
+		t.Fatalf("shape() error = %v", err)
+	}
+	thumbnail := "https://cdn.example.com/mug.jpg"
+	want := []map[string]interface{}{
+		// Fields left out of the JSON, like flash_sale without a running sale, stay out
+		{"id": id.String(), "name": "Mug", "price_cents": float64(1299), "thumbnail": &thumbnail},
+		{"id": id.String(), "name": "Plate", "price_cents": float64(0), "thumbnail": (*string)(nil)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shape() = %v, want %v", got, want)
+	}
+
+	if got, err := fields.shape(nil); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("shape(nil) = %v, %v, want an empty list", got, err)
+	}
+}
@@ -2,38 +2,244 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/internal/webhook"
 	"github.com/sainudheenp/goecom/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// maxSKULookup caps the number of SKUs in a single batch lookup
+const maxSKULookup = 100
+
+// maxProductSuggestions caps the suggestions returned for a search without results
+const maxProductSuggestions = 5
+
+// productSearchColumns maps the allowed search_fields values to their columns
+var productSearchColumns = map[string]string{
+	"name":        "name",
+	"description": "description",
+	"sku":         "sku",
+}
+
+// productSortOrders maps the allowed sort values to their ORDER BY clause
+var productSortOrders = map[string]string{
+	"price_asc":    "price_cents ASC",
+	"price_desc":   "price_cents DESC",
+	"name_asc":     "name ASC",
+	"name_desc":    "name DESC",
+	"created_desc": "created_at DESC",
+	"featured":     "featured_rank ASC NULLS LAST, created_at DESC",
+}
+
 // ProductHandler handles product endpoints
 type ProductHandler struct {
-	db *gorm.DB
+	db                *store.DB
+	lowStockThreshold int
+	defaultCurrency   string
+	hideOutOfStock    bool
+	maxImages         int
+	searchMinLength   int
+	pageSize          int
+	defaultSort       string
+	events            *webhook.Publisher
 }
 
-// NewProductHandler creates a new product handler
-func NewProductHandler(db *gorm.DB) *ProductHandler {
+// NewProductHandler creates a new product handler.
+// Products created without a currency are priced in defaultCurrency, hideOutOfStock
+// sets the default of the public list's in_stock_only filter, maxImages caps the image URLs
+// per product, searchMinLength is the shortest accepted search and pageSize and defaultSort are
+// the default size and sort of product lists. Product lists and lookups read from the store's reader.
+func NewProductHandler(db *store.DB, lowStockThreshold int, defaultCurrency string, hideOutOfStock bool, maxImages int, searchMinLength int, pageSize int, defaultSort string, events *webhook.Publisher) *ProductHandler {
 	return &ProductHandler{
-		db: db,
+		db:                db,
+		lowStockThreshold: lowStockThreshold,
+		defaultCurrency:   defaultCurrency,
+		hideOutOfStock:    hideOutOfStock,
+		maxImages:         maxImages,
+		searchMinLength:   searchMinLength,
+		pageSize:          pageSize,
+		defaultSort:       defaultSort,
+		events:            events,
 	}
 }
 
-// ListProducts lists products with filtering and pagination
+// ListProducts lists published products with filtering and pagination.
+// in_stock_only hides products without stock and defaults to the HIDE_OUT_OF_STOCK setting;
+// min_stock matches products with at least that much stock.
 func (h *ProductHandler) ListProducts(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
-	q := c.Query("q")
+	dbQuery := h.db.Reader().WithContext(c.Request.Context()).Model(&models.Product{}).
+		Where("status = ?", models.ProductStatusPublished)
+
+	inStockOnly, err := strconv.ParseBool(c.DefaultQuery("in_stock_only", strconv.FormatBool(h.hideOutOfStock)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "in_stock_only must be true or false",
+		})
+		return
+	}
+	if inStockOnly {
+		dbQuery = dbQuery.Where("stock > 0")
+	}
+
+	if v := c.Query("min_stock"); v != "" {
+		minStock, err := strconv.Atoi(v)
+		if err != nil || minStock < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "min_stock must be a non-negative integer",
+			})
+			return
+		}
+		dbQuery = dbQuery.Where("stock >= ?", minStock)
+	}
+
+	h.listProducts(c, dbQuery)
+}
+
+// ListAdminProducts lists products in every status for inventory management with stock filters (admin only).
+// low_stock matches products at or below the threshold, out_of_stock matches products with no stock.
+func (h *ProductHandler) ListAdminProducts(c *gin.Context) {
+	dbQuery := h.db.Reader().WithContext(c.Request.Context()).Model(&models.Product{})
+
+	if status := c.Query("status"); status != "" {
+		switch status {
+		case models.ProductStatusDraft, models.ProductStatusPublished, models.ProductStatusArchived:
+			dbQuery = dbQuery.Where("status = ?", status)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "status must be draft, published or archived",
+			})
+			return
+		}
+	}
+
+	threshold := h.lowStockThreshold
+	if v := c.Query("threshold"); v != "" {
+		t, err := strconv.Atoi(v)
+		if err != nil || t < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "threshold must be a non-negative integer",
+			})
+			return
+		}
+		threshold = t
+	}
+
+	lowStock, err := strconv.ParseBool(c.DefaultQuery("low_stock", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "low_stock must be true or false",
+		})
+		return
+	}
+	outOfStock, err := strconv.ParseBool(c.DefaultQuery("out_of_stock", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "out_of_stock must be true or false",
+		})
+		return
+	}
+
+	if lowStock {
+		dbQuery = dbQuery.Where("stock <= ?", threshold)
+	}
+	if outOfStock {
+		dbQuery = dbQuery.Where("stock = 0")
+	}
+
+	h.listProducts(c, dbQuery)
+}
+
+// listProducts applies search, sorting and pagination to a product query and writes the page.
+// With suggest=true, a search without results also returns the products with the most similar names.
+// fields narrows the listed products to the named fields; suggestions are always complete.
+// tags filters to products with any of the comma-separated tags, or all of them with tags_match=all.
+func (h *ProductHandler) listProducts(c *gin.Context, dbQuery *gorm.DB) {
+	page, size, err := parsePagination(c, h.pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	suggest, err := strconv.ParseBool(c.DefaultQuery("suggest", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "suggest must be true or false",
+		})
+		return
+	}
+
+	formatted, err := parseFormatted(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	fields, err := parseProductFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tagsCondition, err := productTagsCondition(c.Query("tags"), c.DefaultQuery("tags_match", "any"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if tagsCondition != nil {
+		dbQuery = dbQuery.Where(tagsCondition)
+	}
+
+	// Very short searches match most of the catalog and can't use an index
+	q := strings.TrimSpace(c.Query("q"))
+	if q != "" && utf8.RuneCountInString(q) < h.searchMinLength {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("q must be at least %d characters", h.searchMinLength),
+		})
+		return
+	}
+	sort := c.DefaultQuery("sort", h.defaultSort)
+
+	orderBy, ok := productSortOrders[sort]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid sort value",
+			"details": "sort must be one of price_asc, price_desc, name_asc, name_desc, created_desc, featured",
+		})
+		return
+	}
 
 	var products []models.Product
-	dbQuery := h.db.Model(&models.Product{})
 
+	// Suggestions keep the caller's filters but not the search itself
+	unsearched := dbQuery
 	if q != "" {
-		dbQuery = dbQuery.Where("name ILIKE ? OR description ILIKE ?", "%"+q+"%", "%"+q+"%")
+		search, err := productSearchCondition(c.DefaultQuery("search_fields", "name,description"), q)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid search_fields value",
+				"details": err.Error(),
+			})
+			return
+		}
+		dbQuery = dbQuery.Session(&gorm.Session{}).Where(search)
 	}
 
 	var total int64
@@ -45,19 +251,106 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 	}
 
 	offset := (page - 1) * size
-	if err := dbQuery.Limit(size).Offset(offset).Find(&products).Error; err != nil {
+	pageQuery := dbQuery.Select(availableStockSelect)
+	if fields != nil {
+		pageQuery = dbQuery.Select(fields.selectColumns())
+	}
+	if err := pageQuery.Order(orderBy).Limit(size).Offset(offset).Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list products",
+		})
+		return
+	}
+	if err := attachProductListFlashSales(h.db.Reader().WithContext(c.Request.Context()), products); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to list products",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	if formatted {
+		for i := range products {
+			products[i].FormatPrices()
+		}
+	}
+
+	response := gin.H{
 		"products": products,
 		"total":    total,
 		"page":     page,
 		"size":     size,
-	})
+	}
+	if fields != nil {
+		shaped, err := fields.shape(products)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to list products",
+			})
+			return
+		}
+		response["products"] = shaped
+	}
+
+	if suggest && q != "" && total == 0 {
+		suggestions, err := productSuggestions(unsearched, q)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to suggest products",
+			})
+			return
+		}
+		if formatted {
+			for i := range suggestions {
+				suggestions[i].FormatPrices()
+			}
+		}
+		response["suggestions"] = suggestions
+	}
+
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, response)
+}
+
+// productSuggestions returns the products whose names are most similar to q using pg_trgm.
+// Names must reach pg_trgm.similarity_threshold (0.3 by default) to be suggested.
+func productSuggestions(dbQuery *gorm.DB, q string) ([]models.Product, error) {
+	suggestions := []models.Product{}
+	err := dbQuery.
+		Where("name % ?", q).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "similarity(name, ?) DESC, name ASC", Vars: []interface{}{q}}}).
+		Limit(maxProductSuggestions).
+		Find(&suggestions).Error
+	return suggestions, err
+}
+
+// productSearchCondition builds an OR of ILIKE matches on the comma-separated search fields.
+// q is matched literally, so % and _ in it aren't wildcards.
+func productSearchCondition(fields, q string) (clause.Expression, error) {
+	pattern := "%" + escapeLike(q) + "%"
+	var exprs []clause.Expression
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		column, ok := productSearchColumns[field]
+		if !ok {
+			return nil, errors.New("search_fields must be a comma-separated list of name, description, sku")
+		}
+		exprs = append(exprs, clause.Expr{SQL: column + " ILIKE ?", Vars: []interface{}{pattern}})
+	}
+	if len(exprs) == 0 {
+		return nil, errors.New("search_fields must name at least one field")
+	}
+	return clause.Or(exprs...), nil
+}
+
+// likeEscaper escapes the LIKE wildcards and the backslash escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes s so a LIKE or ILIKE pattern matches it literally
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
 }
 
 // GetProduct retrieves a product by ID
@@ -67,8 +360,127 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 // @Param id path string true "Product ID"
 // @Success 200 {object} store.Product
 // @Failure 404 {object} ErrorResponse
-// GetProduct retrieves a product by ID
+// GetProduct retrieves a published product by ID
 func (h *ProductHandler) GetProduct(c *gin.Context) {
+	h.getProduct(c, h.db.Reader().WithContext(c.Request.Context()).Where("status = ?", models.ProductStatusPublished))
+}
+
+// GetAdminProduct retrieves a product in any status by ID (admin only)
+func (h *ProductHandler) GetAdminProduct(c *gin.Context) {
+	h.getProduct(c, h.db.Reader().WithContext(c.Request.Context()))
+}
+
+// getProduct looks up the product named by the id parameter in dbQuery and writes it
+func (h *ProductHandler) getProduct(c *gin.Context, dbQuery *gorm.DB) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	formatted, err := parseFormatted(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var product models.Product
+	if err := dbQuery.Select(availableStockSelect).Preload("BundleItems").Preload("PriceTiers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_quantity ASC")
+	}).First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+	if err := attachFlashSales(h.db.Reader().WithContext(c.Request.Context()), []*models.Product{&product}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	if formatted {
+		product.FormatPrices()
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// GetProductsBySKUs looks up published products for a comma-separated list of SKUs.
+// Products are returned in request order and unknown or unpublished SKUs are listed under missing.
+func (h *ProductHandler) GetProductsBySKUs(c *gin.Context) {
+	var skus []string
+	seen := make(map[string]bool)
+	for _, sku := range strings.Split(c.Query("skus"), ",") {
+		sku = strings.TrimSpace(sku)
+		if sku == "" || seen[sku] {
+			continue
+		}
+		seen[sku] = true
+		skus = append(skus, sku)
+	}
+
+	if len(skus) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "skus is required",
+		})
+		return
+	}
+	if len(skus) > maxSKULookup {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "too many skus, maximum is " + strconv.Itoa(maxSKULookup),
+		})
+		return
+	}
+
+	var found []models.Product
+	if err := h.db.Reader().WithContext(c.Request.Context()).Where("sku IN ? AND status = ?", skus, models.ProductStatusPublished).Find(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get products",
+		})
+		return
+	}
+	if err := attachProductListFlashSales(h.db.Reader().WithContext(c.Request.Context()), found); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get products",
+		})
+		return
+	}
+
+	bySKU := make(map[string]models.Product, len(found))
+	for _, product := range found {
+		bySKU[product.SKU] = product
+	}
+
+	products := make([]models.Product, 0, len(found))
+	missing := []string{}
+	for _, sku := range skus {
+		if product, ok := bySKU[sku]; ok {
+			products = append(products, product)
+		} else {
+			missing = append(missing, sku)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"products": products,
+		"missing":  missing,
+	})
+}
+
+// GetStockHistory lists the stock movements for a product (admin only)
+func (h *ProductHandler) GetStockHistory(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -77,8 +489,673 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
+	page, size, err := parsePagination(c, defaultPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var product models.Product
+	if err := h.db.WithContext(c.Request.Context()).Select("id").First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	dbQuery := h.db.WithContext(c.Request.Context()).Model(&models.StockMovement{}).Where("product_id = ?", id)
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count stock movements",
+		})
+		return
+	}
+
+	var movements []models.StockMovement
+	offset := (page - 1) * size
+	if err := dbQuery.Order("created_at DESC").Limit(size).Offset(offset).Find(&movements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list stock movements",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movements": movements,
+		"total":     total,
+		"page":      page,
+		"size":      size,
+	})
+}
+
+var (
+	// errBundleComponentNotFound is returned when a bundle names a product that does not exist
+	errBundleComponentNotFound = errors.New("bundle component not found")
+	// errNestedBundle is returned when a bundle names another bundle as a component
+	errNestedBundle = errors.New("bundles cannot contain bundles")
+)
+
+// BundleItemRequest is one component of a bundle being created
+type BundleItemRequest struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,min=1"`
+}
+
+// CreateProductRequest represents product creation input.
+// Products sold by weight are priced per kilogram and their stock is in grams.
+// Passing bundle_items creates a bundle, whose stock is derived from its components.
+// Products are created as drafts unless status is published.
+type CreateProductRequest struct {
+	SKU         string              `json:"sku" binding:"required,max=64"`
+	Name        string              `json:"name" binding:"required,max=200"`
+	Description string              `json:"description"`
+	PriceCents  *int                `json:"price_cents" binding:"required,min=0,nonzero_price,max_price"`
+	Currency    string              `json:"currency" binding:"omitempty,currency"`
+	UnitType    string              `json:"unit_type" binding:"omitempty,oneof=each weight"`
+	Status      string              `json:"status" binding:"omitempty,oneof=draft published"`
+	Stock       int                 `json:"stock" binding:"min=0"`
+	WeightGrams int                 `json:"weight_grams" binding:"min=0"`
+	LengthMm    int                 `json:"length_mm" binding:"min=0"`
+	WidthMm     int                 `json:"width_mm" binding:"min=0"`
+	HeightMm    int                 `json:"height_mm" binding:"min=0"`
+	Images      []string            `json:"images"`
+	Tags        []string            `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
+	BundleItems []BundleItemRequest `json:"bundle_items" binding:"omitempty,max=20,dive"`
+}
+
+// CreateProduct adds a product to the catalog (admin only)
+func (h *ProductHandler) CreateProduct(c *gin.Context) {
+	var req CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	images, ok := h.productImages(req.Images)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("a product can have at most %d images", h.maxImages),
+		})
+		return
+	}
+
+	isBundle := len(req.BundleItems) > 0
+	if isBundle {
+		if req.Stock != 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "stock cannot be set on a bundle, it follows its components",
+			})
+			return
+		}
+		if req.UnitType == models.UnitTypeWeight {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "bundles cannot be sold by weight",
+			})
+			return
+		}
+		seen := make(map[uuid.UUID]bool, len(req.BundleItems))
+		for _, item := range req.BundleItems {
+			if seen[item.ProductID] {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "bundle_items must not repeat a product",
+				})
+				return
+			}
+			seen[item.ProductID] = true
+		}
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = h.defaultCurrency
+	}
+	unitType := req.UnitType
+	if unitType == "" {
+		unitType = models.UnitTypeEach
+	}
+	status := req.Status
+	if status == "" {
+		status = models.ProductStatusDraft
+	}
+
+	product := &models.Product{
+		SKU:         req.SKU,
+		Name:        req.Name,
+		Description: req.Description,
+		PriceCents:  *req.PriceCents,
+		Currency:    models.NormalizeCurrency(currency),
+		UnitType:    unitType,
+		WeightGrams: req.WeightGrams,
+		LengthMm:    req.LengthMm,
+		WidthMm:     req.WidthMm,
+		HeightMm:    req.HeightMm,
+		Images:      images,
+		Tags:        productTags(req.Tags),
+		IsBundle:    isBundle,
+		Status:      status,
+	}
+
+	err := h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		// A retried attempt starts again from no stock
+		product.Stock = 0
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		if isBundle {
+			return createBundleItems(tx, product, req.BundleItems)
+		}
+		if req.Stock == 0 {
+			return nil
+		}
+		// Initial stock goes through the ledger so stock history adds up
+		if err := incrementStock(tx, product.ID, req.Stock, models.StockReasonAdjust, nil); err != nil {
+			return err
+		}
+		product.Stock = req.Stock
+		return nil
+	})
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "a product with this SKU already exists",
+			})
+		case errors.Is(err, errBundleComponentNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "bundle component not found",
+			})
+		case errors.Is(err, errNestedBundle):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "bundles cannot contain other bundles",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to create product",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, product)
+}
+
+// createBundleItems adds the components of a new bundle and sets its stock from theirs
+func createBundleItems(tx *gorm.DB, bundle *models.Product, items []BundleItemRequest) error {
+	componentIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		componentIDs = append(componentIDs, item.ProductID)
+	}
+
+	var components []models.Product
+	if err := tx.Where("id IN ?", componentIDs).Find(&components).Error; err != nil {
+		return err
+	}
+	if len(components) != len(componentIDs) {
+		return errBundleComponentNotFound
+	}
+	for _, component := range components {
+		if component.IsBundle {
+			return errNestedBundle
+		}
+	}
+
+	bundle.BundleItems = make([]models.BundleItem, 0, len(items))
+	for _, item := range items {
+		bundle.BundleItems = append(bundle.BundleItems, models.BundleItem{
+			BundleID:    bundle.ID,
+			ComponentID: item.ProductID,
+			Quantity:    item.Quantity,
+		})
+	}
+	if err := tx.Create(&bundle.BundleItems).Error; err != nil {
+		return err
+	}
+
+	if err := refreshBundleStock(tx, componentIDs); err != nil {
+		return err
+	}
+	return tx.Model(bundle).Select("stock").First(bundle).Error
+}
+
+// UpdateProductRequest represents product update input.
+// Omitted fields are left unchanged; stock is changed through stock adjustments.
+type UpdateProductRequest struct {
+	Name        *string   `json:"name" binding:"omitempty,min=1,max=200"`
+	Description *string   `json:"description"`
+	PriceCents  *int      `json:"price_cents" binding:"omitempty,min=0,nonzero_price,max_price"`
+	Currency    *string   `json:"currency" binding:"omitempty,currency"`
+	WeightGrams *int      `json:"weight_grams" binding:"omitempty,min=0"`
+	LengthMm    *int      `json:"length_mm" binding:"omitempty,min=0"`
+	WidthMm     *int      `json:"width_mm" binding:"omitempty,min=0"`
+	HeightMm    *int      `json:"height_mm" binding:"omitempty,min=0"`
+	Images      *[]string `json:"images"`
+	Tags        *[]string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=50"`
+}
+
+// UpdateProduct changes a product's details (admin only)
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	var req UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	var images []string
+	if req.Images != nil {
+		var ok bool
+		if images, ok = h.productImages(*req.Images); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("a product can have at most %d images", h.maxImages),
+			})
+			return
+		}
+	}
+
+	var product models.Product
+	if err := h.db.WithContext(c.Request.Context()).First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	if req.Name != nil {
+		product.Name = *req.Name
+	}
+	if req.Description != nil {
+		product.Description = *req.Description
+	}
+	if req.PriceCents != nil {
+		product.PriceCents = *req.PriceCents
+	}
+	if req.Currency != nil {
+		product.Currency = models.NormalizeCurrency(*req.Currency)
+	}
+	if req.WeightGrams != nil {
+		product.WeightGrams = *req.WeightGrams
+	}
+	if req.LengthMm != nil {
+		product.LengthMm = *req.LengthMm
+	}
+	if req.WidthMm != nil {
+		product.WidthMm = *req.WidthMm
+	}
+	if req.HeightMm != nil {
+		product.HeightMm = *req.HeightMm
+	}
+	if req.Images != nil {
+		product.Images = images
+	}
+	if req.Tags != nil {
+		product.Tags = productTags(*req.Tags)
+	}
+
+	// Stock is omitted so concurrent orders and adjustments aren't overwritten
+	if err := h.db.WithContext(c.Request.Context()).Model(&product).
+		Select("name", "description", "price_cents", "currency", "weight_grams", "length_mm", "width_mm", "height_mm", "images", "tags").
+		Updates(&product).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update product",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// productTags trims and lowercases tags and drops empty and repeated ones, keeping the first occurrence of each
+func productTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		unique = append(unique, tag)
+	}
+	return unique
+}
+
+// productTagsCondition matches products with any of the comma-separated tags, or all of them
+// when match is "all". It returns nil when no tags are given.
+func productTagsCondition(tags, match string) (clause.Expression, error) {
+	if match != "any" && match != "all" {
+		return nil, errors.New("tags_match must be any or all")
+	}
+	list := productTags(strings.Split(tags, ","))
+	if len(list) == 0 {
+		return nil, nil
+	}
+	// Containment queries use the GIN index on tags
+	if match == "all" {
+		return clause.Expr{SQL: "products.tags @> ?", Vars: []interface{}{models.JSONStringSlice(list)}}, nil
+	}
+	conditions := make([]string, 0, len(list))
+	vars := make([]interface{}, 0, len(list))
+	for _, tag := range list {
+		conditions = append(conditions, "products.tags @> ?")
+		vars = append(vars, models.JSONStringSlice{tag})
+	}
+	return clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars}, nil
+}
+
+// productImages drops repeated URLs from images, keeping the first occurrence of each.
+// It reports false when more than the allowed number of distinct images remain.
+func (h *ProductHandler) productImages(images []string) ([]string, bool) {
+	seen := make(map[string]bool, len(images))
+	unique := make([]string, 0, len(images))
+	for _, image := range images {
+		if seen[image] {
+			continue
+		}
+		seen[image] = true
+		unique = append(unique, image)
+	}
+	return unique, len(unique) <= h.maxImages
+}
+
+// ProductImageRequest identifies a single product image
+type ProductImageRequest struct {
+	URL string `json:"url" form:"url" binding:"required,url,max=2048"`
+}
+
+// AddProductImage appends an image URL to a product's images (admin only).
+// The append happens in a single UPDATE so concurrent image edits don't overwrite each other
+// or push a product past the image limit; adding a URL the product already has is a no-op.
+func (h *ProductHandler) AddProductImage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	var req ProductImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	result := db.Model(&models.Product{}).
+		Where("id = ? AND NOT COALESCE(images, '[]'::jsonb) @> jsonb_build_array(?::text)", id, req.URL).
+		Where("jsonb_array_length(COALESCE(images, '[]'::jsonb)) < ?", h.maxImages).
+		Update("images", gorm.Expr("COALESCE(images, '[]'::jsonb) || jsonb_build_array(?::text)", req.URL))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to add product image",
+		})
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	// Nothing changed either because the URL is already there or because the product is full
+	if result.RowsAffected == 0 && !slices.Contains(product.Images, req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("a product can have at most %d images", h.maxImages),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// RemoveProductImage removes the image URL given in the url query parameter from a product (admin only).
+// Like AddProductImage it leaves the product's other images untouched.
+func (h *ProductHandler) RemoveProductImage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	var req ProductImageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	result := db.Model(&models.Product{}).
+		Where("id = ? AND images @> jsonb_build_array(?::text)", id, req.URL).
+		Update("images", gorm.Expr("images - ?::text", req.URL))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to remove product image",
+		})
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "image not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// StockAdjustment represents a single stock change in a bulk adjustment
+type StockAdjustment struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Delta     int       `json:"delta" binding:"required"`
+}
+
+// AdjustStockRequest represents bulk stock adjustment input
+type AdjustStockRequest struct {
+	Adjustments []StockAdjustment `json:"adjustments" binding:"required,min=1,dive"`
+	Reason      string            `json:"reason" binding:"omitempty,oneof=adjust import"`
+}
+
+// AdjustStock applies a batch of stock adjustments in a single transaction (admin only)
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	var req AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = models.StockReasonAdjust
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(req.Adjustments))
+	for _, adj := range req.Adjustments {
+		productIDs = append(productIDs, adj.ProductID)
+	}
+	var bundles int64
+	if err := h.db.WithContext(c.Request.Context()).Model(&models.Product{}).
+		Where("id IN ? AND is_bundle", productIDs).
+		Count(&bundles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to adjust stock",
+		})
+		return
+	}
+	if bundles > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "bundle stock follows its components, adjust the components instead",
+		})
+		return
+	}
+
+	err := h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		for _, adj := range req.Adjustments {
+			if adj.Delta < 0 {
+				if err := decrementStock(tx, adj.ProductID, -adj.Delta, reason, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := incrementStock(tx, adj.ProductID, adj.Delta, reason, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errInsufficientStock):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "insufficient stock",
+			})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to adjust stock",
+			})
+		}
+		return
+	}
+
+	notifyBackInStock(c.Request.Context(), h.db, h.events)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "stock adjusted",
+	})
+}
+
+// PublishProduct makes a draft or archived product visible in the public catalog (admin only).
+// Publishing a product that is already published is a no-op.
+func (h *ProductHandler) PublishProduct(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	if err := db.Model(&models.Product{}).
+		Where("id = ? AND status <> ?", id, models.ProductStatusPublished).
+		Update("status", models.ProductStatusPublished).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to publish product",
+		})
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// ArchiveProduct hides a product from the public catalog and stops its sale without deleting it (admin only).
+// Archiving a product that is already archived is a no-op; PublishProduct brings it back.
+func (h *ProductHandler) ArchiveProduct(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+	if err := db.Model(&models.Product{}).
+		Where("id = ? AND status <> ?", id, models.ProductStatusArchived).
+		Update("status", models.ProductStatusArchived).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to archive product",
+		})
+		return
+	}
+
 	var product models.Product
-	if err := h.db.First(&product, id).Error; err != nil {
+	if err := db.First(&product, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "product not found",
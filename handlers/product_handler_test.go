@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	store "github.com/sainudheenp/goecom/db"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a store that builds queries without ever connecting to a database
+func dryRunDB(t *testing.T) *store.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(postgres.Open("host=localhost dbname=test"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+	return &store.DB{DB: gormDB}
+}
+
+func TestProductSortOrders(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"price_asc", "price_cents ASC"},
+		{"price_desc", "price_cents DESC"},
+		{"name_asc", "name ASC"},
+		{"name_desc", "name DESC"},
+		{"created_desc", "created_at DESC"},
+		{"featured", "featured_rank ASC NULLS LAST, created_at DESC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			got, ok := productSortOrders[tt.sort]
+			if !ok || got != tt.want {
+				t.Errorf("productSortOrders[%q] = %q, %v; want %q", tt.sort, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestListProductsRejectsInvalidParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown sort", "?sort=rating_desc"},
+		{"sort in wrong case", "?sort=PRICE_ASC"},
+		{"zero page", "?page=0"},
+		{"negative page", "?page=-1"},
+		{"page not a number", "?page=two"},
+		{"zero size", "?size=0"},
+		{"size not a number", "?size=ten"},
+		{"page too large", "?page=9223372036854775807"},
+	}
+
+	h := &ProductHandler{db: dryRunDB(t), pageSize: defaultPageSize, defaultSort: "created_desc"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/products"+tt.query, nil)
+
+			h.ListProducts(c)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"mug", "mug"},
+		{"100%", `100\%`},
+		{"snake_case", `snake\_case`},
+		{`C:\temp`, `C:\\temp`},
+		{`50%_off\`, `50\%\_off\\`},
+		{`\%`, `\\\%`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestListProductsSearchMinLength(t *testing.T) {
+	tests := []struct {
+		name       string
+		q          string
+		wantStatus int
+	}{
+		{"no search", "", http.StatusOK},
+		{"too short", "ab", http.StatusBadRequest},
+		{"too short once trimmed", "%20ab%20%20", http.StatusBadRequest},
+		{"at the minimum", "mug", http.StatusOK},
+		{"counts characters, not bytes", "%C3%A9t%C3%A9", http.StatusOK},
+		{"wildcards are literal text", "%25%25", http.StatusBadRequest},
+	}
+
+	h := &ProductHandler{db: dryRunDB(t), pageSize: defaultPageSize, searchMinLength: 3, defaultSort: "created_desc"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/products?q="+tt.q, nil)
+
+			h.ListProducts(c)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
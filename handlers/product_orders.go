@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// ProductOrder is an order containing a product, with the quantity of the product it bought
+type ProductOrder struct {
+	models.Order
+	QuantitySold int `json:"quantity_sold"`
+}
+
+// ListProductOrders lists the orders containing a product, newest first, with the quantity of
+// the product each one bought (admin only)
+func (h *OrderHandler) ListProductOrders(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	page, size, err := parsePagination(c, h.pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	db := h.db.Reader().WithContext(c.Request.Context())
+	if err := db.Select("id").First(&models.Product{}, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	itemsQuery := func() *gorm.DB {
+		return db.Table("order_items").
+			Joins("JOIN orders ON orders.id = order_items.order_id").
+			Where("order_items.product_id = ?", productID)
+	}
+
+	var total int64
+	if err := itemsQuery().Distinct("order_items.order_id").Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count orders",
+		})
+		return
+	}
+
+	var rows []struct {
+		OrderID      uuid.UUID
+		QuantitySold int
+	}
+	offset := (page - 1) * size
+	if err := itemsQuery().
+		Select("orders.id AS order_id, SUM(order_items.quantity) AS quantity_sold").
+		Group("orders.id, orders.created_at").
+		Order("orders.created_at DESC, orders.id").
+		Limit(size).Offset(offset).
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list orders",
+		})
+		return
+	}
+
+	orderIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		orderIDs = append(orderIDs, row.OrderID)
+	}
+	var found []models.Order
+	if len(orderIDs) > 0 {
+		if err := db.Preload("User", func(db *gorm.DB) *gorm.DB {
+			// Orders of deactivated users still show who placed them
+			return db.Unscoped()
+		}).Where("id IN ?", orderIDs).Find(&found).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to list orders",
+			})
+			return
+		}
+	}
+	byID := make(map[uuid.UUID]models.Order, len(found))
+	for _, order := range found {
+		byID[order.ID] = order
+	}
+
+	orders := make([]ProductOrder, 0, len(rows))
+	for _, row := range rows {
+		if order, ok := byID[row.OrderID]; ok {
+			orders = append(orders, ProductOrder{Order: order, QuantitySold: row.QuantitySold})
+		}
+	}
+
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, gin.H{
+		"orders": orders,
+		"total":  total,
+		"page":   page,
+		"size":   size,
+	})
+}
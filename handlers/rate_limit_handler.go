@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/middleware"
+)
+
+// RateLimitHandler reports the caller's rate limit quota
+type RateLimitHandler struct {
+	limiter *middleware.RateLimiter
+}
+
+// NewRateLimitHandler creates a new rate limit handler
+func NewRateLimitHandler(limiter *middleware.RateLimiter) *RateLimitHandler {
+	return &RateLimitHandler{
+		limiter: limiter,
+	}
+}
+
+// GetRateLimit returns the caller's read quota at the top level and its write quota under write.
+// The request itself counts against the read quota, so remaining already reflects it.
+func (h *RateLimitHandler) GetRateLimit(c *gin.Context) {
+	quota := h.quota(c.ClientIP(), middleware.RequestClassRead)
+	quota["write"] = h.quota(c.ClientIP(), middleware.RequestClassWrite)
+
+	c.JSON(http.StatusOK, quota)
+}
+
+// quota reports a client's limit, remaining requests and reset time for a request class
+func (h *RateLimitHandler) quota(clientIP, class string) gin.H {
+	remaining, reset := h.limiter.Status(clientIP, class)
+	return gin.H{
+		"limit":         h.limiter.Limit(class),
+		"remaining":     remaining,
+		"reset_seconds": int(math.Ceil(reset.Seconds())),
+	}
+}
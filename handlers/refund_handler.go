@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/internal/webhook"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// errOrderNotRefundable is returned when refunding an order that hasn't been paid
+	errOrderNotRefundable = errors.New("order is not refundable")
+	// errOrderItemNotFound is returned when a refund names an item that isn't on the order
+	errOrderItemNotFound = errors.New("order item not found")
+	// errRefundExceedsPurchase is returned when a refund would return more units than were bought
+	errRefundExceedsPurchase = errors.New("refund exceeds purchased quantity")
+)
+
+// refundableStatuses lists the order statuses that can be refunded
+var refundableStatuses = map[string]bool{
+	models.OrderStatusPaid:    true,
+	models.OrderStatusShipped: true,
+}
+
+// RefundHandler handles order refund endpoints
+type RefundHandler struct {
+	db     *store.DB
+	events *webhook.Publisher
+}
+
+// NewRefundHandler creates a new refund handler
+func NewRefundHandler(db *store.DB, events *webhook.Publisher) *RefundHandler {
+	return &RefundHandler{
+		db:     db,
+		events: events,
+	}
+}
+
+// RefundItemRequest represents one line item to refund
+type RefundItemRequest struct {
+	OrderItemID uuid.UUID `json:"order_item_id" binding:"required"`
+	Quantity    int       `json:"quantity" binding:"required,min=1"`
+}
+
+// CreateRefundRequest represents refund input
+type CreateRefundRequest struct {
+	Items  []RefundItemRequest `json:"items" binding:"required,min=1,max=100,dive"`
+	Reason string              `json:"reason" binding:"max=500"`
+}
+
+// CreateRefund refunds specific units of a paid or shipped order (admin only).
+// The amount is computed from the prices snapshotted on the order, the refunded units are
+// returned to stock, and no item can be refunded more times than it was bought.
+func (h *RefundHandler) CreateRefund(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	var req CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	// Merge repeated items so the purchase limit is checked against the full quantity
+	var itemIDs []uuid.UUID
+	quantities := make(map[uuid.UUID]int)
+	for _, item := range req.Items {
+		if _, ok := quantities[item.OrderItemID]; !ok {
+			itemIDs = append(itemIDs, item.OrderItemID)
+		}
+		quantities[item.OrderItemID] += item.Quantity
+	}
+
+	var refund *models.Refund
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		// Locking the order serializes refunds so concurrent requests can't both pass the limit check
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&order, orderID).Error; err != nil {
+			return err
+		}
+		if !refundableStatuses[order.Status] {
+			return errOrderNotRefundable
+		}
+
+		orderItems := make(map[uuid.UUID]models.OrderItem, len(order.Items))
+		for _, item := range order.Items {
+			orderItems[item.ID] = item
+		}
+
+		refundedQty, err := refundedQuantities(tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		refund = &models.Refund{
+			OrderID:   orderID,
+			Currency:  order.Currency,
+			Reason:    req.Reason,
+			CreatedBy: adminID,
+		}
+		for _, id := range itemIDs {
+			item, ok := orderItems[id]
+			if !ok {
+				return errOrderItemNotFound
+			}
+			qty := quantities[id]
+			if refundedQty[id]+qty > item.Quantity {
+				return errRefundExceedsPurchase
+			}
+
+			amountCents := int(item.LineTotalCents(qty))
+			refund.AmountCents += amountCents
+			refund.Items = append(refund.Items, models.RefundItem{
+				OrderItemID: id,
+				Quantity:    qty,
+				AmountCents: amountCents,
+			})
+		}
+
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		for _, item := range refund.Items {
+			if err := incrementStock(tx, orderItems[item.OrderItemID].ProductID, item.Quantity, models.StockReasonRefund, &refund.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+		case errors.Is(err, errOrderNotRefundable):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "only paid or shipped orders can be refunded",
+			})
+		case errors.Is(err, errOrderItemNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "order item not found",
+			})
+		case errors.Is(err, errRefundExceedsPurchase):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "refund exceeds purchased quantity",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to create refund",
+			})
+		}
+		return
+	}
+
+	notifyBackInStock(c.Request.Context(), h.db, h.events)
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+// refundedQuantities returns the units already refunded for each item of an order
+func refundedQuantities(tx *gorm.DB, orderID uuid.UUID) (map[uuid.UUID]int, error) {
+	var refunded []struct {
+		OrderItemID uuid.UUID
+		Quantity    int
+	}
+	if err := tx.Table("refund_items").
+		Select("refund_items.order_item_id, SUM(refund_items.quantity) AS quantity").
+		Joins("JOIN refunds ON refunds.id = refund_items.refund_id").
+		Where("refunds.order_id = ?", orderID).
+		Group("refund_items.order_item_id").
+		Scan(&refunded).Error; err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[uuid.UUID]int, len(refunded))
+	for _, r := range refunded {
+		quantities[r.OrderItemID] = r.Quantity
+	}
+	return quantities, nil
+}
+
+// unrefundedQuantity returns the units of an order item that are still out of stock,
+// which is what cancelling the order returns since refunds already restocked the rest
+func unrefundedQuantity(item models.OrderItem, refunded map[uuid.UUID]int) int {
+	return max(item.Quantity-refunded[item.ID], 0)
+}
+
+// ListRefunds lists an order's refunds with their item breakdown (admin only)
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid order ID",
+		})
+		return
+	}
+
+	var refunds []models.Refund
+	if err := h.db.WithContext(c.Request.Context()).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("created_at DESC").
+		Find(&refunds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list refunds",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"refunds": refunds,
+	})
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+)
+
+func TestUnrefundedQuantity(t *testing.T) {
+	item := models.OrderItem{ID: uuid.New(), ProductID: uuid.New(), Quantity: 5}
+
+	tests := []struct {
+		name     string
+		refunded map[uuid.UUID]int
+		want     int
+	}{
+		{"no refunds", map[uuid.UUID]int{}, 5},
+		{"partly refunded then cancelled", map[uuid.UUID]int{item.ID: 2}, 3},
+		{"fully refunded then cancelled", map[uuid.UUID]int{item.ID: 5}, 0},
+		{"refunds of other items", map[uuid.UUID]int{uuid.New(): 4}, 5},
+		{"more refunded than bought", map[uuid.UUID]int{item.ID: 7}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unrefundedQuantity(item, tt.refunded); got != tt.want {
+				t.Errorf("unrefundedQuantity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
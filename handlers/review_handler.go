@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reviewSortOrders maps the allowed review sort values to their ORDER BY clause
+var reviewSortOrders = map[string]string{
+	"newest":      "created_at DESC",
+	"rating_desc": "rating DESC, created_at DESC",
+	"rating_asc":  "rating ASC, created_at DESC",
+	"helpful":     "helpful_count DESC, created_at DESC",
+}
+
+// ReviewHandler handles product review endpoints
+type ReviewHandler struct {
+	db *store.DB
+}
+
+// NewReviewHandler creates a new review handler
+func NewReviewHandler(db *store.DB) *ReviewHandler {
+	return &ReviewHandler{
+		db: db,
+	}
+}
+
+// CreateReviewRequest represents review input
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Title  string `json:"title" binding:"max=200"`
+	Body   string `json:"body" binding:"max=5000"`
+}
+
+// CreateReview adds the current user's review of a product
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	var req CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": err.Error(),
+			"fields":  fieldErrors(err),
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+
+	var product models.Product
+	if err := db.Select("id").First(&product, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "product not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get product",
+		})
+		return
+	}
+
+	review := &models.Review{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Title:     req.Title,
+		Body:      req.Body,
+	}
+	if err := db.Create(review).Error; err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "you have already reviewed this product",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to create review",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// ListReviews lists a product's reviews with sorting and a minimum rating filter.
+// The average rating and count always cover all of the product's reviews.
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid product ID",
+		})
+		return
+	}
+
+	page, size, err := parsePagination(c, defaultPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	sort := c.DefaultQuery("sort", "newest")
+
+	orderBy, ok := reviewSortOrders[sort]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid sort value",
+			"details": "sort must be one of newest, rating_desc, rating_asc, helpful",
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+
+	var summary struct {
+		AverageRating float64
+		ReviewCount   int64
+	}
+	if err := db.Model(&models.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average_rating, COUNT(*) AS review_count").
+		Where("product_id = ?", productID).
+		Scan(&summary).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to summarize reviews",
+		})
+		return
+	}
+
+	dbQuery := db.Model(&models.Review{}).Where("product_id = ?", productID)
+
+	if v := c.Query("min_rating"); v != "" {
+		minRating, err := strconv.Atoi(v)
+		if err != nil || minRating < 1 || minRating > 5 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "min_rating must be an integer between 1 and 5",
+			})
+			return
+		}
+		dbQuery = dbQuery.Where("rating >= ?", minRating)
+	}
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count reviews",
+		})
+		return
+	}
+
+	var reviews []models.Review
+	offset := (page - 1) * size
+	if err := dbQuery.Order(orderBy).Limit(size).Offset(offset).Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list reviews",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reviews":        reviews,
+		"average_rating": summary.AverageRating,
+		"review_count":   summary.ReviewCount,
+		"total":          total,
+		"page":           page,
+		"size":           size,
+	})
+}
+
+// MarkHelpful records the current user's helpful vote on a review.
+// Each user can vote once per review; repeat votes leave the count unchanged.
+func (h *ReviewHandler) MarkHelpful(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid review ID",
+		})
+		return
+	}
+
+	var review models.Review
+	err = h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&review, reviewID).Error; err != nil {
+			return err
+		}
+
+		vote := &models.ReviewVote{
+			ReviewID: reviewID,
+			UserID:   userID,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := tx.Model(&models.Review{}).Where("id = ?", reviewID).
+				UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.First(&review, reviewID).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "review not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to record vote",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
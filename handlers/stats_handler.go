@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// maxRevenueBuckets caps the number of buckets a revenue query may return
+const maxRevenueBuckets = 366
+
+// revenueStatuses are the order statuses that count towards revenue
+var revenueStatuses = []string{models.OrderStatusPaid, models.OrderStatusShipped}
+
+// StatsHandler handles admin statistics endpoints
+type StatsHandler struct {
+	db *gorm.DB
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(db *gorm.DB) *StatsHandler {
+	return &StatsHandler{
+		db: db,
+	}
+}
+
+// RevenueBucket represents revenue and order count for a single period
+type RevenueBucket struct {
+	Period       time.Time `json:"period"`
+	RevenueCents int64     `json:"revenue_cents"`
+	OrderCount   int64     `json:"order_count"`
+}
+
+// SourceRevenue represents revenue and order count for a single order source
+type SourceRevenue struct {
+	Source       string `json:"source"`
+	RevenueCents int64  `json:"revenue_cents"`
+	OrderCount   int64  `json:"order_count"`
+}
+
+// GetRevenue returns a revenue time series bucketed by day, week or month (admin only).
+// Totals per order source are included so revenue can be attributed by channel,
+// and the optional source query param restricts everything to one channel.
+func (h *StatsHandler) GetRevenue(c *gin.Context) {
+	interval := c.DefaultQuery("interval", "day")
+	if interval != "day" && interval != "week" && interval != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "interval must be one of day, week, month",
+		})
+		return
+	}
+
+	source := c.Query("source")
+	if source != "" && !models.IsValidOrderSource(source) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "source must be one of " + strings.Join(models.OrderSources, ", "),
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	to := truncateToInterval(now, "day")
+	from := to.AddDate(0, 0, -29)
+
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid from date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid to date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		to = t
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from must not be after to",
+		})
+		return
+	}
+
+	// Build the empty series first so gaps are filled with zero-value buckets
+	start := truncateToInterval(from, interval)
+	var buckets []RevenueBucket
+	index := make(map[time.Time]int)
+	for t := start; !t.After(to); t = nextInterval(t, interval) {
+		if len(buckets) == maxRevenueBuckets {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "date range too large for the requested interval",
+			})
+			return
+		}
+		index[t] = len(buckets)
+		buckets = append(buckets, RevenueBucket{Period: t})
+	}
+
+	orders := h.db.WithContext(c.Request.Context()).Model(&models.Order{}).
+		Where("status IN ? AND created_at >= ? AND created_at < ?", revenueStatuses, start, to.AddDate(0, 0, 1))
+	if source != "" {
+		orders = orders.Where("source = ?", source)
+	}
+
+	var rows []RevenueBucket
+	err := orders.Session(&gorm.Session{}).
+		Select("date_trunc(?, created_at AT TIME ZONE 'UTC') AS period, COALESCE(SUM(total_cents), 0) AS revenue_cents, COUNT(*) AS order_count", interval).
+		Group("period").
+		Order("period").
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to compute revenue",
+		})
+		return
+	}
+
+	for _, row := range rows {
+		if i, ok := index[row.Period.UTC()]; ok {
+			buckets[i].RevenueCents = row.RevenueCents
+			buckets[i].OrderCount = row.OrderCount
+		}
+	}
+
+	sources := []SourceRevenue{}
+	err = orders.Session(&gorm.Session{}).
+		Select("source, COALESCE(SUM(total_cents), 0) AS revenue_cents, COUNT(*) AS order_count").
+		Group("source").
+		Order("revenue_cents DESC").
+		Scan(&sources).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to compute revenue",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"interval": interval,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"buckets":  buckets,
+		"sources":  sources,
+	})
+}
+
+// truncateToInterval truncates t to the start of its day, ISO week or month in UTC,
+// matching Postgres date_trunc
+func truncateToInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case "week":
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextInterval returns the start of the bucket following t
+func nextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// errInsufficientStock is returned when a product does not have enough stock
+var errInsufficientStock = errors.New("insufficient stock")
+
+// decrementStock reduces a product's stock and records the movement.
+// Selling a bundle takes its components out of stock instead.
+// It must be called with a transaction so the movement can't diverge from the stock change.
+func decrementStock(tx *gorm.DB, productID uuid.UUID, quantity int, reason string, referenceID *uuid.UUID) error {
+	return changeStock(tx, productID, -quantity, reason, referenceID)
+}
+
+// incrementStock increases a product's stock and records the movement.
+// Returning a bundle puts its components back in stock instead.
+// It must be called with a transaction so the movement can't diverge from the stock change.
+func incrementStock(tx *gorm.DB, productID uuid.UUID, quantity int, reason string, referenceID *uuid.UUID) error {
+	return changeStock(tx, productID, quantity, reason, referenceID)
+}
+
+// changeStock applies delta to a product, or to each component of a bundle,
+// then refreshes the stock of every bundle containing a changed product
+func changeStock(tx *gorm.DB, productID uuid.UUID, delta int, reason string, referenceID *uuid.UUID) error {
+	var components []models.BundleItem
+	if err := tx.Where("bundle_id = ?", productID).Find(&components).Error; err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		if err := changeProductStock(tx, productID, delta, reason, referenceID); err != nil {
+			return err
+		}
+		return refreshBundleStock(tx, []uuid.UUID{productID})
+	}
+
+	componentIDs := make([]uuid.UUID, 0, len(components))
+	for _, component := range components {
+		if err := changeProductStock(tx, component.ComponentID, delta*component.Quantity, reason, referenceID); err != nil {
+			return err
+		}
+		componentIDs = append(componentIDs, component.ComponentID)
+	}
+	return refreshBundleStock(tx, componentIDs)
+}
+
+// changeProductStock applies delta to a single product's stock column and records the movement
+func changeProductStock(tx *gorm.DB, productID uuid.UUID, delta int, reason string, referenceID *uuid.UUID) error {
+	dbQuery := tx.Model(&models.Product{}).Where("id = ?", productID)
+	if delta < 0 {
+		dbQuery = dbQuery.Where("stock >= ?", -delta)
+	}
+	result := dbQuery.Update("stock", gorm.Expr("stock + ?", delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if delta < 0 {
+			return errInsufficientStock
+		}
+		return gorm.ErrRecordNotFound
+	}
+
+	return recordStockMovement(tx, productID, delta, reason, referenceID)
+}
+
+// refreshBundleStock recomputes the stock of the bundles containing any of the given products
+// as the number of complete bundles their components can make
+func refreshBundleStock(tx *gorm.DB, componentIDs []uuid.UUID) error {
+	return tx.Exec(`
+		UPDATE products b
+		SET stock = COALESCE((
+			SELECT MIN(c.stock / bi.quantity)
+			FROM bundle_items bi
+			JOIN products c ON c.id = bi.component_id
+			WHERE bi.bundle_id = b.id
+		), 0), updated_at = NOW()
+		WHERE b.id IN (SELECT bundle_id FROM bundle_items WHERE component_id IN ?)`,
+		componentIDs).Error
+}
+
+// recordStockMovement writes a stock movement row
+func recordStockMovement(tx *gorm.DB, productID uuid.UUID, delta int, reason string, referenceID *uuid.UUID) error {
+	movement := &models.StockMovement{
+		ProductID:   productID,
+		Delta:       delta,
+		Reason:      reason,
+		ReferenceID: referenceID,
+	}
+	return tx.Create(movement).Error
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// availableStockExpr is a product's stock minus its active holds
+const availableStockExpr = `products.stock - COALESCE((
+	SELECT SUM(h.quantity) FROM stock_holds h
+	WHERE h.product_id = products.id AND h.expires_at > NOW()
+), 0)`
+
+// availableStockSelect adds each product's stock minus its active holds as available_stock
+const availableStockSelect = "products.*, " + availableStockExpr + " AS available_stock"
+
+// heldByOthers returns the quantity of each product held by active holds of users other than userID
+func heldByOthers(db *gorm.DB, productIDs []uuid.UUID, userID uuid.UUID) (map[uuid.UUID]int, error) {
+	held := make(map[uuid.UUID]int, len(productIDs))
+	if len(productIDs) == 0 {
+		return held, nil
+	}
+
+	var rows []struct {
+		ProductID uuid.UUID
+		Quantity  int
+	}
+	if err := db.Model(&models.StockHold{}).
+		Select("product_id, SUM(quantity) AS quantity").
+		Where("product_id IN ? AND user_id <> ? AND expires_at > ?", productIDs, userID, time.Now().UTC()).
+		Group("product_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		held[row.ProductID] = row.Quantity
+	}
+	return held, nil
+}
+
+// placeStockHolds replaces the user's holds with holds on the given items that expire after ttl
+// and returns the expiry. It must be called with a transaction.
+// A hold on a bundle holds the bundle only, not its components, so a component sold on its own
+// can still take stock the held bundles need; checkout then fails with insufficient stock.
+func placeStockHolds(tx *gorm.DB, userID uuid.UUID, items []models.OrderItem, ttl time.Duration) (time.Time, error) {
+	expiresAt := time.Now().UTC().Add(ttl)
+	if err := releaseStockHolds(tx, userID); err != nil {
+		return expiresAt, err
+	}
+	if len(items) == 0 {
+		return expiresAt, nil
+	}
+
+	holds := make([]models.StockHold, 0, len(items))
+	for _, item := range items {
+		holds = append(holds, models.StockHold{
+			UserID:    userID,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			ExpiresAt: expiresAt,
+		})
+	}
+	return expiresAt, tx.Create(&holds).Error
+}
+
+// releaseStockHolds deletes all of the user's holds
+func releaseStockHolds(db *gorm.DB, userID uuid.UUID) error {
+	return db.Where("user_id = ?", userID).Delete(&models.StockHold{}).Error
+}
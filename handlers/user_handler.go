@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	store "github.com/sainudheenp/goecom/db"
+	"github.com/sainudheenp/goecom/internal/webhook"
+	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// UserHandler handles admin user management endpoints
+type UserHandler struct {
+	db         *store.DB
+	roles      *middleware.Roles
+	bcryptCost int
+	inviteTTL  time.Duration
+	events     *webhook.Publisher
+}
+
+// NewUserHandler creates a new user handler.
+// Imported users may be given a role defined in roles. Imported plaintext passwords are
+// hashed with bcryptCost and invites expire after inviteTTL.
+func NewUserHandler(db *store.DB, roles *middleware.Roles, bcryptCost int, inviteTTL time.Duration, events *webhook.Publisher) *UserHandler {
+	return &UserHandler{
+		db:         db,
+		roles:      roles,
+		bcryptCost: bcryptCost,
+		inviteTTL:  inviteTTL,
+		events:     events,
+	}
+}
+
+// ListUsers lists user accounts (admin only).
+// Deactivated users are only included with include_deleted=true.
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	page, size, err := parsePagination(c, defaultPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	includeDeleted, err := strconv.ParseBool(c.DefaultQuery("include_deleted", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "include_deleted must be true or false",
+		})
+		return
+	}
+
+	dbQuery := h.db.WithContext(c.Request.Context()).Model(&models.User{})
+	if includeDeleted {
+		dbQuery = dbQuery.Unscoped()
+	}
+	if q := c.Query("q"); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		dbQuery = dbQuery.Where("email ILIKE ? OR username ILIKE ? OR full_name ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to count users",
+		})
+		return
+	}
+
+	var users []models.User
+	offset := (page - 1) * size
+	if err := dbQuery.Order("created_at DESC").Limit(size).Offset(offset).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to list users",
+		})
+		return
+	}
+
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
+
+// DeleteUser deactivates a user account so it can no longer log in (admin only)
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	if id == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "you cannot deactivate your own account",
+		})
+		return
+	}
+
+	result := h.db.WithContext(c.Request.Context()).Delete(&models.User{}, id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to deactivate user",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreUser reactivates a deactivated user account (admin only)
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	db := h.db.WithContext(c.Request.Context())
+
+	var user models.User
+	if err := db.Unscoped().First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "user not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to get user",
+		})
+		return
+	}
+
+	if user.DeletedAt.Valid {
+		if err := db.Unscoped().Model(&user).Update("deleted_at", nil).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to restore user",
+			})
+			return
+		}
+		user.DeletedAt = gorm.DeletedAt{}
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/internal/webhook"
+	"github.com/sainudheenp/goecom/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// maxImportUsers caps the rows of one import; every row with a plaintext password costs a bcrypt hash
+const maxImportUsers = 500
+
+// Per-row import outcomes
+const (
+	importStatusCreated = "created"
+	importStatusSkipped = "skipped"
+	importStatusFailed  = "failed"
+)
+
+// UserInvitedEvent is the webhook payload sent when an imported user is invited to choose a password.
+// The receiver delivers Token to the user, who sets their password with POST /auth/set-password.
+type UserInvitedEvent struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	FullName  string    `json:"full_name"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ImportUserRow is one user to import.
+// Exactly one of password, password_hash and send_invite must be set. Role defaults to user
+// and may otherwise name a role defined in ROLE_PERMISSIONS.
+type ImportUserRow struct {
+	Email        string `json:"email" binding:"required,email"`
+	FullName     string `json:"full_name" binding:"required,max=200"`
+	Role         string `json:"role" binding:"omitempty,max=50"`
+	Password     string `json:"password" binding:"omitempty,min=8"`
+	PasswordHash string `json:"password_hash"`
+	SendInvite   bool   `json:"send_invite"`
+}
+
+// hasOneCredential reports whether exactly one of password, password_hash and send_invite is set
+func (r *ImportUserRow) hasOneCredential() bool {
+	credentials := 0
+	for _, set := range []bool{r.Password != "", r.PasswordHash != "", r.SendInvite} {
+		if set {
+			credentials++
+		}
+	}
+	return credentials == 1
+}
+
+// ImportUsersRequest represents a JSON user import
+type ImportUsersRequest struct {
+	Users []ImportUserRow `json:"users" binding:"required,min=1"`
+}
+
+// ImportUserResult is the outcome of one imported row; Row counts from 1
+type ImportUserResult struct {
+	Row    int               `json:"row"`
+	Email  string            `json:"email"`
+	Status string            `json:"status"`
+	UserID *uuid.UUID        `json:"user_id,omitempty"`
+	Error  string            `json:"error,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// ImportUsers creates many user accounts at once (admin only).
+// Users are sent as JSON ({"users": [...]}) or as CSV (Content-Type: text/csv) with a header row
+// naming the columns email, full_name, role, password, password_hash and send_invite.
+// Each row is imported on its own: rows whose email is taken are skipped, invalid rows fail,
+// and the response reports every row's outcome.
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	var rows []ImportUserRow
+	if c.ContentType() == "text/csv" {
+		var err error
+		if rows, err = parseImportCSV(c.Request.Body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	} else {
+		var req ImportUsersRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid request",
+				"details": err.Error(),
+				"fields":  fieldErrors(err),
+			})
+			return
+		}
+		rows = req.Users
+	}
+
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "no users to import",
+		})
+		return
+	}
+	if len(rows) > maxImportUsers {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("at most %d users can be imported at once", maxImportUsers),
+		})
+		return
+	}
+
+	results := make([]ImportUserResult, 0, len(rows))
+	counts := make(map[string]int)
+	for i, row := range rows {
+		result := h.importUser(c, &row)
+		result.Row = i + 1
+		counts[result.Status]++
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"created": counts[importStatusCreated],
+		"skipped": counts[importStatusSkipped],
+		"failed":  counts[importStatusFailed],
+	})
+}
+
+// importUser validates and creates one imported user
+func (h *UserHandler) importUser(c *gin.Context, row *ImportUserRow) ImportUserResult {
+	row.Email = strings.TrimSpace(row.Email)
+	row.FullName = strings.TrimSpace(row.FullName)
+	result := ImportUserResult{Email: row.Email}
+
+	if err := binding.Validator.ValidateStruct(row); err != nil {
+		result.Status = importStatusFailed
+		result.Error = "invalid row"
+		result.Fields = fieldErrors(err)
+		return result
+	}
+
+	if row.Role != "" && row.Role != "user" && !h.roles.Defined(row.Role) {
+		result.Status = importStatusFailed
+		result.Error = "role must be user or a role defined in ROLE_PERMISSIONS"
+		return result
+	}
+
+	if !row.hasOneCredential() {
+		result.Status = importStatusFailed
+		result.Error = "exactly one of password, password_hash and send_invite is required"
+		return result
+	}
+
+	user := &models.User{
+		Email:    row.Email,
+		FullName: row.FullName,
+		Role:     row.Role,
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	switch {
+	case row.Password != "":
+		hash, err := bcrypt.GenerateFromPassword([]byte(row.Password), h.bcryptCost)
+		if err != nil {
+			result.Status = importStatusFailed
+			result.Error = "failed to hash password"
+			return result
+		}
+		user.PasswordHash = string(hash)
+	case row.PasswordHash != "":
+		if _, err := bcrypt.Cost([]byte(row.PasswordHash)); err != nil {
+			result.Status = importStatusFailed
+			result.Error = "password_hash must be a bcrypt hash"
+			return result
+		}
+		user.PasswordHash = row.PasswordHash
+	}
+	// Invited users have no password hash, so they can't log in until they set one
+
+	err := h.db.WithTransaction(c.Request.Context(), func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if !row.SendInvite {
+			return nil
+		}
+
+		token, expiresAt, err := createPasswordSetupToken(tx, user.ID, h.inviteTTL)
+		if err != nil {
+			return err
+		}
+		return h.events.Enqueue(tx, webhook.EventUserInvited, UserInvitedEvent{
+			UserID:    user.ID,
+			Email:     user.Email,
+			FullName:  user.FullName,
+			Token:     token,
+			ExpiresAt: expiresAt,
+		})
+	})
+	if err != nil {
+		// The unique email index catches both existing users and repeats within the import
+		if isUniqueViolation(err) {
+			result.Status = importStatusSkipped
+			result.Error = "user already exists"
+			return result
+		}
+		result.Status = importStatusFailed
+		result.Error = "failed to create user"
+		return result
+	}
+
+	result.Status = importStatusCreated
+	result.UserID = &user.ID
+	return result
+}
+
+// parseImportCSV reads import rows from CSV with a header row.
+// email and full_name columns are required; unknown columns are ignored.
+func parseImportCSV(r io.Reader) ([]ImportUserRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("csv must start with a header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok && name != "" {
+			return nil, fmt.Errorf("csv header repeats the %s column", name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"email", "full_name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing the %s column", required)
+		}
+	}
+
+	var rows []ImportUserRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %v", err)
+		}
+		if len(rows) == maxImportUsers {
+			return nil, fmt.Errorf("at most %d users can be imported at once", maxImportUsers)
+		}
+
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := ImportUserRow{
+			Email:        get("email"),
+			FullName:     get("full_name"),
+			Role:         get("role"),
+			Password:     get("password"),
+			PasswordHash: get("password_hash"),
+		}
+		if v := get("send_invite"); v != "" {
+			if row.SendInvite, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("line %d: send_invite must be true or false", line)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// createPasswordSetupToken stores a new single-use password setup token for the user and returns it
+func createPasswordSetupToken(tx *gorm.DB, userID uuid.UUID, ttl time.Duration) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt := time.Now().Add(ttl)
+
+	if err := tx.Create(&models.PasswordSetupToken{
+		UserID:    userID,
+		TokenHash: hashPasswordSetupToken(token),
+		ExpiresAt: expiresAt,
+	}).Error; err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// hashPasswordSetupToken returns the hex SHA-256 hash under which a token is stored
+func hashPasswordSetupToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/middleware"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []ImportUserRow
+		wantErr string
+	}{
+		{
+			name: "all columns",
+			csv: "email,full_name,role,password,password_hash,send_invite\n" +
+				"ann@example.com,Ann Lee,admin,correct-horse,,\n" +
+				"bob@example.com,Bob Roy,,,,true\n",
+			want: []ImportUserRow{
+				{Email: "ann@example.com", FullName: "Ann Lee", Role: "admin", Password: "correct-horse"},
+				{Email: "bob@example.com", FullName: "Bob Roy", SendInvite: true},
+			},
+		},
+		{
+			name: "header case, spacing and column order",
+			csv:  " Full_Name , EMAIL ,notes\n\"Lee, Ann\",  ann@example.com ,ignored\n",
+			want: []ImportUserRow{{Email: "ann@example.com", FullName: "Lee, Ann"}},
+		},
+		{
+			name: "short rows",
+			csv:  "email,full_name,send_invite\nann@example.com\n",
+			want: []ImportUserRow{{Email: "ann@example.com"}},
+		},
+		{
+			// Repeated emails stay separate rows; the import reports the repeat as skipped
+			name: "duplicate emails",
+			csv:  "email,full_name,send_invite\nann@example.com,Ann,true\nann@example.com,Ann Lee,true\n",
+			want: []ImportUserRow{
+				{Email: "ann@example.com", FullName: "Ann", SendInvite: true},
+				{Email: "ann@example.com", FullName: "Ann Lee", SendInvite: true},
+			},
+		},
+		{
+			name: "header only",
+			csv:  "email,full_name\n",
+		},
+		{
+			name:    "duplicate column",
+			csv:     "email,full_name,Email\nann@example.com,Ann,bob@example.com\n",
+			wantErr: "csv header repeats the email column",
+		},
+		{
+			name:    "empty body",
+			csv:     "",
+			wantErr: "csv must start with a header row",
+		},
+		{
+			name:    "missing required column",
+			csv:     "email,role\nann@example.com,user\n",
+			wantErr: "csv header is missing the full_name column",
+		},
+		{
+			name:    "invalid send_invite",
+			csv:     "email,full_name,send_invite\nann@example.com,Ann,true\nbob@example.com,Bob,maybe\n",
+			wantErr: "line 3: send_invite must be true or false",
+		},
+		{
+			name:    "malformed quoting",
+			csv:     "email,full_name\nann@example.com,\"Ann\n",
+			wantErr: "invalid csv",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseImportCSV(strings.NewReader(tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("parseImportCSV() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseImportCSV() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseImportCSV() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportCSVRowLimit(t *testing.T) {
+	csv := "email,full_name\n" + strings.Repeat("ann@example.com,Ann\n", maxImportUsers)
+	if rows, err := parseImportCSV(strings.NewReader(csv)); err != nil || len(rows) != maxImportUsers {
+		t.Fatalf("parseImportCSV(%d rows) = %d rows, %v", maxImportUsers, len(rows), err)
+	}
+
+	csv += "bob@example.com,Bob\n"
+	if _, err := parseImportCSV(strings.NewReader(csv)); err == nil {
+		t.Errorf("parseImportCSV(%d rows) error = nil, want the row limit", maxImportUsers+1)
+	}
+}
+
+func TestImportUserRowHasOneCredential(t *testing.T) {
+	const hash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+	tests := []struct {
+		name string
+		row  ImportUserRow
+		want bool
+	}{
+		{"password", ImportUserRow{Password: "correct-horse"}, true},
+		{"password hash", ImportUserRow{PasswordHash: hash}, true},
+		{"invite", ImportUserRow{SendInvite: true}, true},
+		{"none", ImportUserRow{}, false},
+		{"password and hash", ImportUserRow{Password: "correct-horse", PasswordHash: hash}, false},
+		{"password and invite", ImportUserRow{Password: "correct-horse", SendInvite: true}, false},
+		{"hash and invite", ImportUserRow{PasswordHash: hash, SendInvite: true}, false},
+		{"all three", ImportUserRow{Password: "correct-horse", PasswordHash: hash, SendInvite: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.row.hasOneCredential(); got != tt.want {
+				t.Errorf("hasOneCredential() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportUserRejectsInvalidRows(t *testing.T) {
+	roles, err := middleware.NewRoles("admin=*;support=orders:read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &UserHandler{db: dryRunDB(t), roles: roles}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/import", nil)
+
+	tests := []struct {
+		name       string
+		row        ImportUserRow
+		wantError  string
+		wantFields map[string]string
+	}{
+		{
+			name:       "invalid email",
+			row:        ImportUserRow{Email: "not-an-email", FullName: "Ann", SendInvite: true},
+			wantError:  "invalid row",
+			wantFields: map[string]string{"email": "email"},
+		},
+		{
+			name:       "missing name",
+			row:        ImportUserRow{Email: "ann@example.com", FullName: "   ", SendInvite: true},
+			wantError:  "invalid row",
+			wantFields: map[string]string{"full_name": "required"},
+		},
+		{
+			name:      "unknown role",
+			row:       ImportUserRow{Email: "ann@example.com", FullName: "Ann", Role: "owner", SendInvite: true},
+			wantError: "role must be user or a role defined in ROLE_PERMISSIONS",
+		},
+		{
+			name:      "no credential",
+			row:       ImportUserRow{Email: "ann@example.com", FullName: "Ann"},
+			wantError: "exactly one of password, password_hash and send_invite is required",
+		},
+		{
+			name:      "two credentials",
+			row:       ImportUserRow{Email: "ann@example.com", FullName: "Ann", Password: "correct-horse", SendInvite: true},
+			wantError: "exactly one of password, password_hash and send_invite is required",
+		},
+		{
+			name:      "hash that isn't bcrypt",
+			row:       ImportUserRow{Email: "ann@example.com", FullName: "Ann", PasswordHash: "5f4dcc3b5aa765d61d8327deb882cf99"},
+			wantError: "password_hash must be a bcrypt hash",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.importUser(c, &tt.row)
+			if got.Status != importStatusFailed || got.Error != tt.wantError {
+				t.Fatalf("importUser() = %s %q, want %s %q", got.Status, got.Error, importStatusFailed, tt.wantError)
+			}
+			if !reflect.DeepEqual(got.Fields, tt.wantFields) {
+				t.Errorf("importUser() fields = %v, want %v", got.Fields, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainudheenp/goecom/models"
+)
+
+// allowedCurrencies holds the currency codes accepted by the currency validation tag
+var allowedCurrencies = map[string]bool{}
+
+// PriceLimits are the guard rails applied to product prices
+type PriceLimits struct {
+	// AllowFree lets products be priced at 0
+	AllowFree bool
+	// MaxCents is the highest accepted price, in minor units of any currency
+	MaxCents int
+}
+
+// priceLimits holds the guard rails checked by the nonzero_price and max_price validation tags
+var priceLimits PriceLimits
+
+// RegisterValidation configures the request validator to report fields by their JSON names
+// and registers the custom tags:
+//   - currency accepts the given ISO 4217 codes in any case
+//   - nonzero_price rejects a price of 0 unless free products are allowed
+//   - max_price rejects prices above the configured ceiling
+func RegisterValidation(currencies []string, limits PriceLimits) {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	for _, currency := range currencies {
+		allowedCurrencies[models.NormalizeCurrency(currency)] = true
+	}
+	priceLimits = limits
+	_ = v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return allowedCurrencies[models.NormalizeCurrency(fl.Field().String())]
+	})
+	_ = v.RegisterValidation("nonzero_price", func(fl validator.FieldLevel) bool {
+		return priceLimits.AllowFree || fl.Field().Int() != 0
+	})
+	_ = v.RegisterValidation("max_price", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int() <= int64(priceLimits.MaxCents)
+	})
+
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return fld.Name
+		}
+		return name
+	})
+}
+
+// fieldErrors converts validation errors into a map of JSON field path to failed rule
+func fieldErrors(err error) map[string]string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make(map[string]string, len(validationErrs))
+	for _, fe := range validationErrs {
+		// Drop the request struct name from the namespace
+		path := fe.Namespace()
+		if i := strings.Index(path, "."); i >= 0 {
+			path = path[i+1:]
+		}
+
+		msg := fe.Tag()
+		if fe.Param() != "" {
+			msg += "=" + fe.Param()
+		}
+		fields[path] = msg
+	}
+
+	return fields
+}
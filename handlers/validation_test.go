@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	RegisterValidation(testCurrencies, PriceLimits{MaxCents: 10000000})
+}
+
+var testCurrencies = []string{"USD", "EUR", "GBP"}
+
+// bindJSON binds body into req the same way the handlers do
+func bindJSON(t *testing.T, body string, req interface{}) error {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c.ShouldBindJSON(req)
+}
+
+func TestCreateOrderRequestShippingAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFields map[string]string
+	}{
+		{
+			name: "complete address",
+			body: `{"shipping_address":{"line1":"1 Main St","city":"Springfield","country":"US","postcode":"12345"}}`,
+		},
+		{
+			name:       "missing country",
+			body:       `{"shipping_address":{"line1":"1 Main St","city":"Springfield","postcode":"12345"}}`,
+			wantFields: map[string]string{"shipping_address.country": "required"},
+		},
+		{
+			name: "missing several fields",
+			body: `{"shipping_address":{"line1":"1 Main St"}}`,
+			wantFields: map[string]string{
+				"shipping_address.city":     "required",
+				"shipping_address.country":  "required",
+				"shipping_address.postcode": "required",
+			},
+		},
+		{
+			name: "saved address instead",
+			body: `{"address_id":"6f1c2a9e-4b57-4c1f-9d3e-2a8f1b7c5d40"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateOrderRequest
+			err := bindJSON(t, tt.body, &req)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("bind error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("bind error = nil, want validation error")
+			}
+			got := fieldErrors(err)
+			if len(got) != len(tt.wantFields) {
+				t.Fatalf("fieldErrors() = %v, want %v", got, tt.wantFields)
+			}
+			for field, rule := range tt.wantFields {
+				if got[field] != rule {
+					t.Errorf("fieldErrors()[%q] = %q, want %q", field, got[field], rule)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want map[string]string
+	}{
+		{
+			name: "rule without a param",
+			body: `{"name":"Mug","price_cents":500,"currency":"USD"}`,
+			want: map[string]string{"sku": "required"},
+		},
+		{
+			name: "rule param is kept",
+			body: `{"sku":"MUG-1","name":"Mug","price_cents":500,"currency":"USD","stock":-1}`,
+			want: map[string]string{"stock": "min=0"},
+		},
+		{
+			name: "several fields",
+			body: `{"sku":"MUG-1","name":"Mug","price_cents":-5,"currency":"XYZ"}`,
+			want: map[string]string{"price_cents": "min=0", "currency": "currency"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateProductRequest
+			got := fieldErrors(bindJSON(t, tt.body, &req))
+			if len(got) != len(tt.want) {
+				t.Fatalf("fieldErrors() = %v, want %v", got, tt.want)
+			}
+			for field, rule := range tt.want {
+				if got[field] != rule {
+					t.Errorf("fieldErrors()[%q] = %q, want %q", field, got[field], rule)
+				}
+			}
+		})
+	}
+
+	if got := fieldErrors(errors.New("unexpected EOF")); got != nil {
+		t.Errorf("fieldErrors(non-validation error) = %v, want nil", got)
+	}
+}
+
+func TestCurrencyValidation(t *testing.T) {
+	tests := []struct {
+		currency string
+		valid    bool
+	}{
+		{"USD", true},
+		{"eur", true},
+		{" gbp ", true},
+		{"JPY", false},
+		{"US", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			body := `{"sku":"MUG-1","name":"Mug","price_cents":500,"currency":"` + tt.currency + `"}`
+			// Products created without a currency are priced in the default one
+			var create CreateProductRequest
+			if wantValid := tt.valid || tt.currency == ""; (bindJSON(t, body, &create) == nil) != wantValid {
+				t.Errorf("create with currency %q: want valid %v", tt.currency, wantValid)
+			}
+
+			var update UpdateProductRequest
+			if err := bindJSON(t, `{"currency":"`+tt.currency+`"}`, &update); (err == nil) != tt.valid {
+				t.Errorf("update with currency %q: error = %v, want valid %v", tt.currency, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestPriceValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    PriceLimits
+		price     string
+		wantField string
+	}{
+		{"ordinary price", PriceLimits{MaxCents: 10000}, "500", ""},
+		{"free rejected", PriceLimits{MaxCents: 10000}, "0", "nonzero_price"},
+		{"free allowed", PriceLimits{AllowFree: true, MaxCents: 10000}, "0", ""},
+		{"negative", PriceLimits{AllowFree: true, MaxCents: 10000}, "-1", "min=0"},
+		{"at the ceiling", PriceLimits{MaxCents: 10000}, "10000", ""},
+		{"above the ceiling", PriceLimits{MaxCents: 10000}, "10001", "max_price"},
+	}
+	t.Cleanup(func() { RegisterValidation(testCurrencies, PriceLimits{MaxCents: 10000000}) })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RegisterValidation(testCurrencies, tt.limits)
+
+			var create CreateProductRequest
+			got := fieldErrors(bindJSON(t, `{"sku":"MUG-1","name":"Mug","price_cents":`+tt.price+`}`, &create))
+			if got["price_cents"] != tt.wantField {
+				t.Errorf("create: fieldErrors()[price_cents] = %q, want %q", got["price_cents"], tt.wantField)
+			}
+
+			var update UpdateProductRequest
+			got = fieldErrors(bindJSON(t, `{"price_cents":`+tt.price+`}`, &update))
+			if got["price_cents"] != tt.wantField {
+				t.Errorf("update: fieldErrors()[price_cents] = %q, want %q", got["price_cents"], tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/internal/version"
+)
+
+// VersionHandler reports which build is running
+type VersionHandler struct {
+	info version.Info
+}
+
+// NewVersionHandler creates a new version handler
+func NewVersionHandler(info version.Info) *VersionHandler {
+	return &VersionHandler{
+		info: info,
+	}
+}
+
+// GetVersion returns the version, commit, build time and Go version of the running build
+func (h *VersionHandler) GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, h.info)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/internal/version"
+)
+
+func TestGetVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		info version.Info
+		want map[string]string
+	}{
+		{
+			name: "injected values",
+			info: version.New("v1.4.0", "3f2c1ab", "2024-05-01T12:00:00Z"),
+			want: map[string]string{"version": "v1.4.0", "commit": "3f2c1ab", "build_time": "2024-05-01T12:00:00Z", "go_version": runtime.Version()},
+		},
+		{
+			name: "defaults",
+			info: version.New("", "", ""),
+			want: map[string]string{"version": "dev", "commit": "unknown", "build_time": "unknown", "go_version": runtime.Version()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/version", nil)
+
+			NewVersionHandler(tt.info).GetVersion(c)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			var got map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("body is not a JSON object of strings: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("keys = %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("%s = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
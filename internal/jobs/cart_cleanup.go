@@ -0,0 +1,83 @@
+// Package jobs contains background jobs started with the server.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// CartCleanup periodically deletes cart items that haven't been touched within a TTL,
+// along with expired checkout stock holds
+type CartCleanup struct {
+	db       *gorm.DB
+	ttl      time.Duration
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewCartCleanup creates a new cart cleanup job
+func NewCartCleanup(db *gorm.DB, ttl, interval time.Duration) *CartCleanup {
+	return &CartCleanup{
+		db:       db,
+		ttl:      ttl,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the job in the background, pruning once immediately and then on every interval
+func (j *CartCleanup) Start() {
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.prune()
+
+			select {
+			case <-j.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop signals the job to exit and waits for a running prune to finish or ctx to be done
+func (j *CartCleanup) Stop(ctx context.Context) error {
+	close(j.stop)
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// prune deletes cart items last updated before the TTL cutoff and expired stock holds
+func (j *CartCleanup) prune() {
+	cutoff := time.Now().UTC().Add(-j.ttl)
+
+	result := j.db.Where("updated_at < ?", cutoff).Delete(&models.CartItem{})
+	if result.Error != nil {
+		log.Printf("Failed to prune expired cart items: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Pruned %d cart items not updated since %s", result.RowsAffected, cutoff.Format(time.RFC3339))
+	}
+
+	// Expired holds are already ignored; this only keeps the table small
+	if err := j.db.Where("expires_at < ?", time.Now().UTC()).Delete(&models.StockHold{}).Error; err != nil {
+		log.Printf("Failed to prune expired stock holds: %v", err)
+	}
+}
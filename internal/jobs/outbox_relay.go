@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sainudheenp/goecom/internal/webhook"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// outboxBatchSize caps the events delivered per relay transaction
+	outboxBatchSize = 100
+	// outboxRetention is how long sent events are kept before they are deleted
+	outboxRetention = 7 * 24 * time.Hour
+)
+
+// OutboxRelay periodically delivers unsent outbox events and marks them sent.
+// Events are locked with SKIP LOCKED, so several servers can run the relay at once.
+type OutboxRelay struct {
+	db        *gorm.DB
+	publisher *webhook.Publisher
+	interval  time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewOutboxRelay creates a new outbox relay job
+func NewOutboxRelay(db *gorm.DB, publisher *webhook.Publisher, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		db:        db,
+		publisher: publisher,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the job in the background, relaying once immediately and then on every interval
+func (j *OutboxRelay) Start() {
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.relay()
+			j.prune()
+
+			select {
+			case <-j.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop signals the job to exit and waits for a running relay to finish or ctx to be done
+func (j *OutboxRelay) Stop(ctx context.Context) error {
+	close(j.stop)
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// relay delivers batches of unsent events until none are left or a batch fails
+func (j *OutboxRelay) relay() {
+	for {
+		delivered, total, err := j.relayBatch()
+		if err != nil {
+			log.Printf("Failed to relay outbox events: %v", err)
+			return
+		}
+		// Stop on a short batch or when nothing got through, so failing events wait for the next interval
+		if total < outboxBatchSize || delivered == 0 {
+			return
+		}
+	}
+}
+
+// relayBatch delivers one batch of unsent events in a transaction that holds their row locks.
+// It returns the number of events delivered and the batch size.
+func (j *OutboxRelay) relayBatch() (int, int, error) {
+	var delivered, total int
+	err := j.db.Transaction(func(tx *gorm.DB) error {
+		delivered, total = 0, 0
+
+		var events []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL").
+			Order("created_at ASC").
+			Limit(outboxBatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		total = len(events)
+
+		for i := range events {
+			event := &events[i]
+			if err := j.publisher.Deliver(context.Background(), event); err != nil {
+				log.Printf("Failed to deliver %s event %s: %v", event.Type, event.ID, err)
+				if err := tx.Model(event).Updates(map[string]interface{}{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": err.Error(),
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Model(event).Updates(map[string]interface{}{
+				"attempts": gorm.Expr("attempts + 1"),
+				"sent_at":  time.Now().UTC(),
+			}).Error; err != nil {
+				return err
+			}
+			delivered++
+		}
+		return nil
+	})
+	return delivered, total, err
+}
+
+// prune deletes events sent before the retention cutoff
+func (j *OutboxRelay) prune() {
+	cutoff := time.Now().UTC().Add(-outboxRetention)
+	if err := j.db.Where("sent_at < ?", cutoff).Delete(&models.OutboxEvent{}).Error; err != nil {
+		log.Printf("Failed to prune sent outbox events: %v", err)
+	}
+}
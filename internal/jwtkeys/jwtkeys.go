@@ -0,0 +1,123 @@
+// Package jwtkeys signs and verifies access tokens with either an HMAC secret (HS256)
+// or an RSA key pair (RS256), and publishes RSA public keys as a JWKS.
+package jwtkeys
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// Keys signs and verifies tokens with a single algorithm
+type Keys struct {
+	method     jwt.SigningMethod
+	secret     []byte
+	privateKey *rsa.PrivateKey
+	keyID      string
+}
+
+// NewHMAC creates keys that sign with HS256 using secret
+func NewHMAC(secret string) *Keys {
+	return &Keys{
+		method: jwt.SigningMethodHS256,
+		secret: []byte(secret),
+	}
+}
+
+// NewRSA creates keys that sign with RS256 using the PEM-encoded private key in path
+func NewRSA(path string) (*Keys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+	}
+
+	// The key ID is derived from the public key so it changes when the key is rotated
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JWT public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+
+	return &Keys{
+		method:     jwt.SigningMethodRS256,
+		privateKey: privateKey,
+		keyID:      base64.RawURLEncoding.EncodeToString(sum[:16]),
+	}, nil
+}
+
+// Algorithm returns the signing algorithm name
+func (k *Keys) Algorithm() string {
+	return k.method.Alg()
+}
+
+// Sign returns a signed token for claims
+func (k *Keys) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.privateKey != nil {
+		token.Header["kid"] = k.keyID
+		return token.SignedString(k.privateKey)
+	}
+	return token.SignedString(k.secret)
+}
+
+// Parse verifies a token's signature and returns it.
+// Tokens signed with any other algorithm are rejected.
+func (k *Keys) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if k.privateKey != nil {
+			return &k.privateKey.PublicKey, nil
+		}
+		return k.secret, nil
+	}, jwt.WithValidMethods([]string{k.method.Alg()}))
+}
+
+// JWK is a JSON Web Key for an RSA public key
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys that verify tokens.
+// The set is empty for HS256 since the secret must not be published.
+func (k *Keys) JWKS() JWKS {
+	if k.privateKey == nil {
+		return JWKS{Keys: []JWK{}}
+	}
+
+	publicKey := k.privateKey.PublicKey
+	return JWKS{
+		Keys: []JWK{{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: AlgorithmRS256,
+			KeyID:     k.keyID,
+			Modulus:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	}
+}
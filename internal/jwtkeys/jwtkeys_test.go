@@ -0,0 +1,172 @@
+package jwtkeys
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret-that-is-at-least-32-characters"
+
+// newTestRSA writes a fresh RSA private key to a temporary PEM file and loads it
+func newTestRSA(t *testing.T) (*Keys, *rsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := NewRSA(path)
+	if err != nil {
+		t.Fatalf("NewRSA() error = %v", err)
+	}
+	return keys, privateKey
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id": "6f1c2a9e-4b57-4c1f-9d3e-2a8f1b7c5d40",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestSignAndParseRoundTrip(t *testing.T) {
+	rsaKeys, _ := newTestRSA(t)
+
+	tests := []struct {
+		name    string
+		keys    *Keys
+		wantAlg string
+		wantKID bool
+	}{
+		{"HS256", NewHMAC(testSecret), AlgorithmHS256, false},
+		{"RS256", rsaKeys, AlgorithmRS256, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.keys.Algorithm(); got != tt.wantAlg {
+				t.Errorf("Algorithm() = %q, want %q", got, tt.wantAlg)
+			}
+
+			signed, err := tt.keys.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			token, err := tt.keys.Parse(signed)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if token.Method.Alg() != tt.wantAlg {
+				t.Errorf("token alg = %q, want %q", token.Method.Alg(), tt.wantAlg)
+			}
+			if _, hasKID := token.Header["kid"]; hasKID != tt.wantKID {
+				t.Errorf("kid header present = %v, want %v", hasKID, tt.wantKID)
+			}
+			if claims := token.Claims.(jwt.MapClaims); claims["user_id"] != testClaims()["user_id"] {
+				t.Errorf("user_id = %v", claims["user_id"])
+			}
+		})
+	}
+}
+
+func TestParseRejectsOtherAlgorithms(t *testing.T) {
+	rsaKeys, privateKey := newTestRSA(t)
+	hmacKeys := NewHMAC(testSecret)
+
+	hs256Token, err := hmacKeys.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256Token, err := rsaKeys.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An HS256 token keyed with the RSA public key, as in an algorithm confusion attack
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	confusedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).
+		SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An HS384 token signed with the right secret
+	hs384Token, err := jwt.NewWithClaims(jwt.SigningMethodHS384, testClaims()).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		keys  *Keys
+		token string
+	}{
+		{"HS256 token for RS256 keys", rsaKeys, hs256Token},
+		{"RS256 token for HS256 keys", hmacKeys, rs256Token},
+		{"HS256 token keyed with the public key", rsaKeys, confusedToken},
+		{"HS384 token for HS256 keys", hmacKeys, hs384Token},
+		{"HS256 token with another secret", NewHMAC("another-secret-that-is-32-characters-long"), hs256Token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.keys.Parse(tt.token); err == nil {
+				t.Error("Parse() error = nil, want the token to be rejected")
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	if keys := NewHMAC(testSecret).JWKS().Keys; len(keys) != 0 {
+		t.Errorf("HS256 JWKS has %d keys, want none", len(keys))
+	}
+
+	rsaKeys, privateKey := newTestRSA(t)
+	jwks := rsaKeys.JWKS()
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("RS256 JWKS has %d keys, want 1", len(jwks.Keys))
+	}
+	jwk := jwks.Keys[0]
+
+	if jwk.KeyType != "RSA" || jwk.Use != "sig" || jwk.Algorithm != AlgorithmRS256 {
+		t.Errorf("kty/use/alg = %s/%s/%s, want RSA/sig/RS256", jwk.KeyType, jwk.Use, jwk.Algorithm)
+	}
+	if jwk.Exponent != "AQAB" {
+		t.Errorf("e = %q, want AQAB", jwk.Exponent)
+	}
+	modulus, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+	if err != nil {
+		t.Fatalf("n is not unpadded base64url: %v", err)
+	}
+	if new(big.Int).SetBytes(modulus).Cmp(privateKey.N) != 0 {
+		t.Error("n does not decode to the key's modulus")
+	}
+
+	// Tokens name the key that verifies them
+	signed, err := rsaKeys.Sign(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Header["kid"] != jwk.KeyID || jwk.KeyID == "" {
+		t.Errorf("token kid = %v, JWKS kid = %q", token.Header["kid"], jwk.KeyID)
+	}
+}
@@ -0,0 +1,38 @@
+// Package version describes the running build.
+package version
+
+import "runtime"
+
+// Defaults reported when a value was not injected at build time
+const (
+	DefaultVersion   = "dev"
+	DefaultCommit    = "unknown"
+	DefaultBuildTime = "unknown"
+)
+
+// Info identifies a build of the server
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// New returns the build info for the given values, falling back to the defaults for empty ones
+func New(version, commit, buildTime string) Info {
+	if version == "" {
+		version = DefaultVersion
+	}
+	if commit == "" {
+		commit = DefaultCommit
+	}
+	if buildTime == "" {
+		buildTime = DefaultBuildTime
+	}
+	return Info{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/internal/requestid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+// Event types sent to webhook endpoints
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventProductBackInStock = "product.back_in_stock"
+	EventUserInvited        = "user.invited"
+)
+
+// Event is the envelope shared by all webhook payloads.
+// RequestID is the ID of the request that triggered the event, when there was one.
+type Event struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	RequestID  string      `json:"request_id,omitempty"`
+	Data       interface{} `json:"data"`
+}
+
+// Publisher writes events to the transactional outbox and delivers them to every configured endpoint
+type Publisher struct {
+	client    *Client
+	endpoints []Endpoint
+}
+
+// NewPublisher creates a new publisher.
+// A publisher without endpoints drops every event.
+func NewPublisher(client *Client, endpoints []Endpoint) *Publisher {
+	return &Publisher{
+		client:    client,
+		endpoints: endpoints,
+	}
+}
+
+// Enabled reports whether events are delivered anywhere
+func (p *Publisher) Enabled() bool {
+	return p != nil && len(p.endpoints) > 0
+}
+
+// Enqueue writes an event to the outbox using tx, so it is only published if tx commits.
+// The request ID is taken from tx's context.
+func (p *Publisher) Enqueue(tx *gorm.DB, eventType string, data interface{}) error {
+	if !p.Enabled() {
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	return tx.Create(&models.OutboxEvent{
+		Type:      eventType,
+		Payload:   string(payload),
+		RequestID: requestid.FromContext(tx.Statement.Context),
+	}).Error
+}
+
+// Deliver sends an outbox event to every endpoint and returns the failures joined together.
+// Endpoints that already received the event get it again when a delivery is retried,
+// so receivers should deduplicate on the event ID.
+func (p *Publisher) Deliver(ctx context.Context, outboxEvent *models.OutboxEvent) error {
+	event := Event{
+		ID:         outboxEvent.ID,
+		Type:       outboxEvent.Type,
+		OccurredAt: outboxEvent.CreatedAt.UTC(),
+		RequestID:  outboxEvent.RequestID,
+		Data:       json.RawMessage(outboxEvent.Payload),
+	}
+	ctx = requestid.NewContext(ctx, outboxEvent.RequestID)
+
+	var errs []error
+	for _, endpoint := range p.endpoints {
+		if err := p.client.Deliver(ctx, endpoint, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", endpoint.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
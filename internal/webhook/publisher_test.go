@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/internal/requestid"
+	"github.com/sainudheenp/goecom/models"
+)
+
+func TestPublisherEnabled(t *testing.T) {
+	client := NewClient(nil, time.Second, 1, time.Millisecond)
+
+	var nilPublisher *Publisher
+	if nilPublisher.Enabled() {
+		t.Error("nil publisher is enabled")
+	}
+	if NewPublisher(client, nil).Enabled() {
+		t.Error("publisher without endpoints is enabled")
+	}
+	if !NewPublisher(client, []Endpoint{{URL: "http://example.com"}}).Enabled() {
+		t.Error("publisher with an endpoint is disabled")
+	}
+}
+
+func TestPublisherDeliversOutboxEvent(t *testing.T) {
+	first, firstRec := newTestServer(t)
+	second, secondRec := newTestServer(t)
+	client := NewClient(nil, time.Second, 1, time.Millisecond)
+	publisher := NewPublisher(client, []Endpoint{
+		{URL: first.URL, Secret: "one"},
+		{URL: second.URL, Secret: "two"},
+	})
+
+	outboxEvent := &models.OutboxEvent{
+		ID:        uuid.New(),
+		Type:      EventOrderCreated,
+		Payload:   `{"order_id":"abc"}`,
+		RequestID: "req-456",
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if err := publisher.Deliver(context.Background(), outboxEvent); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	for i, rec := range []*recorder{firstRec, secondRec} {
+		if rec.attempts() != 1 {
+			t.Fatalf("endpoint %d attempts = %d, want 1", i+1, rec.attempts())
+		}
+		secret := []string{"one", "two"}[i]
+		if !Verify(secret, rec.bodies[0], rec.requests[0].Header.Get(SignatureHeader)) {
+			t.Errorf("endpoint %d signature does not verify with its secret", i+1)
+		}
+		if got := rec.requests[0].Header.Get(requestid.Header); got != "req-456" {
+			t.Errorf("endpoint %d %s = %q, want req-456", i+1, requestid.Header, got)
+		}
+
+		var event struct {
+			ID         uuid.UUID       `json:"id"`
+			Type       string          `json:"type"`
+			OccurredAt time.Time       `json:"occurred_at"`
+			RequestID  string          `json:"request_id"`
+			Data       json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(rec.bodies[0], &event); err != nil {
+			t.Fatalf("endpoint %d body is not an event: %v", i+1, err)
+		}
+		if event.ID != outboxEvent.ID || event.Type != EventOrderCreated || event.RequestID != "req-456" ||
+			!event.OccurredAt.Equal(outboxEvent.CreatedAt) || string(event.Data) != outboxEvent.Payload {
+			t.Errorf("endpoint %d event = %+v", i+1, event)
+		}
+	}
+}
+
+func TestPublisherDeliverJoinsEndpointFailures(t *testing.T) {
+	ok, okRec := newTestServer(t)
+	failing, _ := newTestServer(t, http.StatusInternalServerError)
+	client := NewClient(nil, time.Second, 2, time.Millisecond)
+	publisher := NewPublisher(client, []Endpoint{{URL: failing.URL}, {URL: ok.URL}})
+
+	err := publisher.Deliver(context.Background(), &models.OutboxEvent{ID: uuid.New(), Type: EventOrderCreated, Payload: `{}`})
+	if !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("Deliver() error = %v, want %v", err, ErrDeliveryFailed)
+	}
+	if !strings.Contains(err.Error(), failing.URL) || strings.Contains(err.Error(), ok.URL) {
+		t.Errorf("error %q should name only the failing endpoint", err)
+	}
+	// A failing endpoint doesn't stop delivery to the others
+	if okRec.attempts() != 1 {
+		t.Errorf("healthy endpoint attempts = %d, want 1", okRec.attempts())
+	}
+}
@@ -0,0 +1,162 @@
+// Package webhook delivers signed outbound webhooks and records each delivery attempt.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/internal/requestid"
+	"github.com/sainudheenp/goecom/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// SignatureHeader carries the hex-encoded HMAC-SHA256 of the request body
+	SignatureHeader = "X-Signature"
+	// DeliveryHeader carries an ID shared by all attempts of one delivery
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+// ErrDeliveryFailed is returned when all delivery attempts fail
+var ErrDeliveryFailed = errors.New("webhook delivery failed")
+
+// Endpoint is a webhook destination and the secret used to sign its payloads
+type Endpoint struct {
+	URL    string
+	Secret string
+}
+
+// Client delivers webhooks with retries
+type Client struct {
+	db          *gorm.DB
+	httpClient  *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewClient creates a new webhook client.
+// Delivery attempts are recorded in db when it is not nil.
+func NewClient(db *gorm.DB, timeout time.Duration, maxAttempts int, backoff time.Duration) *Client {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &Client{
+		db:          db,
+		httpClient:  &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body using secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid signature of body using secret
+func Verify(secret string, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Deliver posts payload as JSON to endpoint, retrying with exponential backoff
+// on network errors and 5xx responses. 4xx responses are not retried.
+// A request ID in ctx is sent in the X-Request-ID header.
+func (c *Client) Deliver(ctx context.Context, endpoint Endpoint, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	deliveryID := uuid.New()
+	signature := Sign(endpoint.Secret, body)
+	wait := c.backoff
+
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		statusCode, err := c.send(ctx, endpoint.URL, deliveryID, signature, body)
+		c.record(ctx, deliveryID, endpoint.URL, attempt, statusCode, err)
+
+		if err == nil && statusCode < 300 {
+			return nil
+		}
+		// Client errors won't succeed on retry
+		if err == nil && statusCode < 500 {
+			return fmt.Errorf("%w: status %d", ErrDeliveryFailed, statusCode)
+		}
+		if attempt == c.maxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+
+	return fmt.Errorf("%w: gave up after %d attempts", ErrDeliveryFailed, c.maxAttempts)
+}
+
+// send performs a single delivery attempt
+func (c *Client) send(ctx context.Context, url string, deliveryID uuid.UUID, signature string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+	req.Header.Set(DeliveryHeader, deliveryID.String())
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// record stores a delivery attempt for observability
+func (c *Client) record(ctx context.Context, deliveryID uuid.UUID, url string, attempt, statusCode int, sendErr error) {
+	if c.db == nil {
+		return
+	}
+
+	delivery := &models.WebhookDelivery{
+		DeliveryID: deliveryID,
+		URL:        url,
+		Attempt:    attempt,
+		StatusCode: statusCode,
+		Success:    sendErr == nil && statusCode >= 200 && statusCode < 300,
+	}
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := c.db.WithContext(context.WithoutCancel(ctx)).Create(delivery).Error; err != nil {
+		log.Printf("Failed to record webhook delivery %s: %v", deliveryID, err)
+	}
+}
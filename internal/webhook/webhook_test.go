@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sainudheenp/goecom/internal/requestid"
+)
+
+// recorder is a test endpoint that answers with the next of its statuses and keeps every request it gets
+type recorder struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := http.StatusOK
+	if n := len(r.requests); n < len(r.statuses) {
+		status = r.statuses[n]
+	} else if len(r.statuses) > 0 {
+		status = r.statuses[len(r.statuses)-1]
+	}
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(status)
+}
+
+func (r *recorder) attempts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newTestServer(t *testing.T, statuses ...int) (*httptest.Server, *recorder) {
+	t.Helper()
+	rec := &recorder{statuses: statuses}
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"order.created"}`)
+	signature := Sign("secret", body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"valid", "secret", body, signature, true},
+		{"wrong secret", "other", body, signature, false},
+		{"tampered body", "secret", []byte(`{"type":"order.deleted"}`), signature, false},
+		{"not hex", "secret", body, "not-a-signature", false},
+		{"empty", "secret", body, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliverSignsPayload(t *testing.T) {
+	srv, rec := newTestServer(t)
+	client := NewClient(nil, time.Second, 3, time.Millisecond)
+
+	ctx := requestid.NewContext(context.Background(), "req-123")
+	if err := client.Deliver(ctx, Endpoint{URL: srv.URL, Secret: "secret"}, map[string]string{"hello": "world"}); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	if rec.attempts() != 1 {
+		t.Fatalf("attempts = %d, want 1", rec.attempts())
+	}
+	req, body := rec.requests[0], rec.bodies[0]
+	if string(body) != `{"hello":"world"}` {
+		t.Errorf("body = %s", body)
+	}
+	if !Verify("secret", body, req.Header.Get(SignatureHeader)) {
+		t.Errorf("signature %q does not verify", req.Header.Get(SignatureHeader))
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if req.Header.Get(DeliveryHeader) == "" {
+		t.Error("delivery header missing")
+	}
+	if got := req.Header.Get(requestid.Header); got != "req-123" {
+		t.Errorf("%s = %q, want req-123", requestid.Header, got)
+	}
+}
+
+func TestDeliverRetriesServerErrors(t *testing.T) {
+	srv, rec := newTestServer(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+	client := NewClient(nil, time.Second, 5, time.Millisecond)
+
+	if err := client.Deliver(context.Background(), Endpoint{URL: srv.URL, Secret: "secret"}, "payload"); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	if rec.attempts() != 3 {
+		t.Fatalf("attempts = %d, want 3", rec.attempts())
+	}
+	deliveryID := rec.requests[0].Header.Get(DeliveryHeader)
+	for i, req := range rec.requests {
+		if got := req.Header.Get(DeliveryHeader); got != deliveryID {
+			t.Errorf("attempt %d delivery ID = %q, want %q", i+1, got, deliveryID)
+		}
+		if !Verify("secret", rec.bodies[i], req.Header.Get(SignatureHeader)) {
+			t.Errorf("attempt %d signature does not verify", i+1)
+		}
+	}
+}
+
+func TestDeliverGivesUpAfterMaxAttempts(t *testing.T) {
+	srv, rec := newTestServer(t, http.StatusServiceUnavailable)
+	client := NewClient(nil, time.Second, 3, 10*time.Millisecond)
+
+	start := time.Now()
+	err := client.Deliver(context.Background(), Endpoint{URL: srv.URL}, "payload")
+	if !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("Deliver() error = %v, want %v", err, ErrDeliveryFailed)
+	}
+	if rec.attempts() != 3 {
+		t.Errorf("attempts = %d, want 3", rec.attempts())
+	}
+	// Backoff doubles between attempts: 10ms then 20ms
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("gave up after %v, want at least 30ms of backoff", elapsed)
+	}
+}
+
+func TestDeliverDoesNotRetryClientErrors(t *testing.T) {
+	srv, rec := newTestServer(t, http.StatusBadRequest)
+	client := NewClient(nil, time.Second, 3, time.Millisecond)
+
+	err := client.Deliver(context.Background(), Endpoint{URL: srv.URL}, "payload")
+	if !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("Deliver() error = %v, want %v", err, ErrDeliveryFailed)
+	}
+	if rec.attempts() != 1 {
+		t.Errorf("attempts = %d, want 1", rec.attempts())
+	}
+}
+
+func TestDeliverStopsWhenContextIsCancelled(t *testing.T) {
+	srv, rec := newTestServer(t, http.StatusInternalServerError)
+	client := NewClient(nil, time.Second, 5, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Deliver(ctx, Endpoint{URL: srv.URL}, "payload"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Deliver() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if rec.attempts() != 1 {
+		t.Errorf("attempts = %d, want 1", rec.attempts())
+	}
+}
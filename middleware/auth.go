@@ -2,19 +2,19 @@ package middleware
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/internal/jwtkeys"
 	"github.com/sainudheenp/goecom/models"
 	"gorm.io/gorm"
 )
 
 // AuthMiddleware validates JWT tokens and sets user context
-func AuthMiddleware(db *gorm.DB, jwtSecret string) gin.HandlerFunc {
+func AuthMiddleware(db *gorm.DB, keys *jwtkeys.Keys) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -38,12 +38,7 @@ func AuthMiddleware(db *gorm.DB, jwtSecret string) gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// Validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
+		token, err := keys.Parse(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "invalid or expired token",
@@ -82,7 +77,7 @@ func AuthMiddleware(db *gorm.DB, jwtSecret string) gin.HandlerFunc {
 
 		// Get user from database
 		var user models.User
-		if err := db.First(&user, userID).Error; err != nil {
+		if err := db.WithContext(c.Request.Context()).First(&user, userID).Error; err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "user not found",
 			})
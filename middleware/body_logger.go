@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLoggedBodyBytes caps how much of a request body is buffered for logging
+const maxLoggedBodyBytes = 64 << 10
+
+// redacted replaces sensitive values in logged requests
+const redacted = "[REDACTED]"
+
+// sensitiveFields are matched against JSON keys; any key containing one of them is redacted
+var sensitiveFields = []string{"password", "token", "secret"}
+
+// sensitiveHeaders are never logged in the clear
+var sensitiveHeaders = []string{"Authorization", "Cookie"}
+
+// RequestBodyLogger logs the headers and body of requests that end in an error response.
+// Passwords, tokens, secrets and credentials headers are redacted, and bodies that aren't
+// JSON or are too large are summarized rather than logged. Meant for debugging only.
+func RequestBodyLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var captured bytes.Buffer
+		if c.Request.Body != nil {
+			// Keep a copy of what the handler reads; the handler still sees the full body
+			c.Request.Body = readCloser{
+				Reader: io.TeeReader(c.Request.Body, &limitedWriter{buf: &captured, max: maxLoggedBodyBytes + 1}),
+				Closer: c.Request.Body,
+			}
+		}
+
+		c.Next()
+
+		if c.Writer.Status() < http.StatusBadRequest {
+			return
+		}
+
+		requestID, _ := c.Get("request_id")
+		log.Printf("DEBUG request [%s] %s %s status=%d headers=%s body=%s",
+			requestID,
+			c.Request.Method,
+			c.Request.URL.Path,
+			c.Writer.Status(),
+			redactHeaders(c.Request.Header),
+			redactBody(captured.Bytes()),
+		)
+	}
+}
+
+// redactHeaders renders request headers with credentials replaced
+func redactHeaders(header http.Header) string {
+	safe := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if safe.Get(name) != "" {
+			safe.Set(name, redacted)
+		}
+	}
+	out, _ := json.Marshal(safe)
+	return string(out)
+}
+
+// redactBody renders a JSON body with sensitive fields replaced
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return "-"
+	}
+	if len(body) > maxLoggedBodyBytes {
+		return "[body too large to log]"
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "[non-JSON body omitted]"
+	}
+	out, _ := json.Marshal(redactValue(value))
+	return string(out)
+}
+
+// redactValue walks a decoded JSON value and replaces sensitive fields
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// isSensitiveField reports whether a JSON key may hold a credential
+func isSensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range sensitiveFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// readCloser pairs a reader with the original body's Close
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// limitedWriter buffers up to max bytes and silently drops the rest
+type limitedWriter struct {
+	buf *bytes.Buffer
+	max int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if remaining := w.max - w.buf.Len(); remaining > 0 {
+		w.buf.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", "", "-"},
+		{"non-JSON", "email=a@example.com&password=hunter2", "[non-JSON body omitted]"},
+		{"no sensitive fields", `{"email":"a@example.com","quantity":2}`, `{"email":"a@example.com","quantity":2}`},
+		{"password", `{"email":"a@example.com","password":"hunter2"}`, `{"email":"a@example.com","password":"[REDACTED]"}`},
+		{"matches any key containing a field", `{"new_password":"x","refresh_token":"y","client_secret":"z"}`,
+			`{"client_secret":"[REDACTED]","new_password":"[REDACTED]","refresh_token":"[REDACTED]"}`},
+		{"case-insensitive", `{"Password":"x","API_TOKEN":"y"}`, `{"API_TOKEN":"[REDACTED]","Password":"[REDACTED]"}`},
+		{"nested object", `{"user":{"name":"a","password":"x"}}`, `{"user":{"name":"a","password":"[REDACTED]"}}`},
+		{"array of objects", `[{"token":"x"},{"sku":"A-1"}]`, `[{"token":"[REDACTED]"},{"sku":"A-1"}]`},
+		{"sensitive object replaced whole", `{"secret":{"key":"x"}}`, `{"secret":"[REDACTED]"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactBody([]byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactBodyTooLarge(t *testing.T) {
+	body := `{"data":"` + strings.Repeat("a", maxLoggedBodyBytes) + `"}`
+	if got := redactBody([]byte(body)); got != "[body too large to log]" {
+		t.Errorf("redactBody() = %.40s, want the too-large summary", got)
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc.def")
+	header.Set("Cookie", "session=xyz")
+	header.Set("Content-Type", "application/json")
+
+	var got map[string][]string
+	if err := json.Unmarshal([]byte(redactHeaders(header)), &got); err != nil {
+		t.Fatalf("redactHeaders() is not JSON: %v", err)
+	}
+	for name, want := range map[string]string{"Authorization": redacted, "Cookie": redacted, "Content-Type": "application/json"} {
+		if len(got[name]) != 1 || got[name][0] != want {
+			t.Errorf("%s = %v, want [%s]", name, got[name], want)
+		}
+	}
+	// The request's own headers are left untouched
+	if header.Get("Authorization") != "Bearer abc.def" {
+		t.Error("redactHeaders modified the request headers")
+	}
+}
+
+func TestRequestBodyLogger(t *testing.T) {
+	var logged bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestBodyLogger())
+	var seen string
+	router.POST("/login", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		seen = string(body)
+		c.Status(http.StatusUnauthorized)
+	})
+	router.POST("/ok", func(c *gin.Context) {
+		_, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusOK)
+	})
+
+	body := `{"email":"a@example.com","password":"hunter2"}`
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer abc.def")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if seen != body {
+		t.Errorf("handler read %q, want the full body", seen)
+	}
+	out := logged.String()
+	for _, leak := range []string{"hunter2", "abc.def"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("log contains %q: %s", leak, out)
+		}
+	}
+	for _, want := range []string{"POST /login", "status=401", `"email":"a@example.com"`, redacted} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q: %s", want, out)
+		}
+	}
+
+	logged.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/ok", strings.NewReader(body)))
+	if logged.Len() != 0 {
+		t.Errorf("successful request was logged: %s", logged.String())
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PublicCache lets shared caches such as CDNs keep successful responses for maxAgeSeconds.
+// Error responses are marked no-store so a transient failure isn't cached.
+func PublicCache(maxAgeSeconds int) gin.HandlerFunc {
+	value := "public, max-age=" + strconv.Itoa(maxAgeSeconds)
+
+	return func(c *gin.Context) {
+		writer := &cacheControlWriter{ResponseWriter: c.Writer, value: value}
+		c.Writer = writer
+		c.Next()
+		// Responses without a body have their headers written by gin after the handlers return
+		writer.setCacheControl()
+	}
+}
+
+// NoStore marks every response as uncacheable, for endpoints that serve per-user or admin data
+func NoStore() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.Next()
+	}
+}
+
+// cacheControlWriter sets Cache-Control once the response status is known, just before
+// the headers are written
+type cacheControlWriter struct {
+	gin.ResponseWriter
+	value string
+}
+
+func (w *cacheControlWriter) WriteHeaderNow() {
+	w.setCacheControl()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheControlWriter) Write(data []byte) (int, error) {
+	w.setCacheControl()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheControlWriter) WriteString(s string) (int, error) {
+	w.setCacheControl()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// setCacheControl sets the header unless the headers are already written or the handler set its own
+func (w *cacheControlWriter) setCacheControl() {
+	if w.Written() || w.Header().Get("Cache-Control") != "" {
+		return
+	}
+	if w.Status() >= http.StatusBadRequest {
+		w.Header().Set("Cache-Control", "no-store")
+		return
+	}
+	w.Header().Set("Cache-Control", w.value)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPublicCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		want    string
+	}{
+		{
+			name:    "success",
+			handler: func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": []string{}}) },
+			want:    "public, max-age=60",
+		},
+		{
+			name:    "success without a body",
+			handler: func(c *gin.Context) { c.Status(http.StatusNoContent) },
+			want:    "public, max-age=60",
+		},
+		{
+			name:    "client error",
+			handler: func(c *gin.Context) { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"}) },
+			want:    "no-store",
+		},
+		{
+			name:    "server error",
+			handler: func(c *gin.Context) { c.AbortWithStatus(http.StatusInternalServerError) },
+			want:    "no-store",
+		},
+		{
+			name: "handler sets its own",
+			handler: func(c *gin.Context) {
+				c.Header("Cache-Control", "private")
+				c.String(http.StatusOK, "ok")
+			},
+			want: "private",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/products", PublicCache(60), tt.handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/me", NoStore(), func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": "1"}) })
+	router.GET("/me/orders", NoStore(), func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	})
+
+	for _, path := range []string{"/me", "/me/orders"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := w.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("%s Cache-Control = %q, want no-store", path, got)
+		}
+	}
+}
@@ -6,25 +6,29 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sainudheenp/goecom/internal/requestid"
 )
 
-// RequestID adds a unique request ID to each request
+// RequestID adds a unique request ID to each request.
+// The ID is also stored in the request context so outbound calls can propagate it.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
+		requestID := c.GetHeader(requestid.Header)
 		if requestID == "" {
 			requestID = uuid.New().String()
 		}
 
 		c.Set("request_id", requestID)
-		c.Header("X-Request-ID", requestID)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestID))
+		c.Header(requestid.Header, requestID)
 
 		c.Next()
 	}
 }
 
-// Logger logs HTTP requests
-func Logger() gin.HandlerFunc {
+// Logger logs HTTP requests.
+// Requests slower than slowThreshold are also logged as warnings; zero disables this.
+func Logger(slowThreshold time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -46,5 +50,16 @@ func Logger() gin.HandlerFunc {
 			latency,
 			c.ClientIP(),
 		)
+
+		if slowThreshold > 0 && latency > slowThreshold {
+			log.Printf("WARN slow request [%s] %s %s status=%d latency=%s threshold=%s",
+				requestID,
+				c.Request.Method,
+				path,
+				c.Writer.Status(),
+				latency,
+				slowThreshold,
+			)
+		}
 	}
 }
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoggerWarnsOnSlowRequests(t *testing.T) {
+	var buf bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		delay     time.Duration
+		wantWarn  bool
+	}{
+		{"slow request", 5 * time.Millisecond, 20 * time.Millisecond, true},
+		{"fast request", time.Second, 0, false},
+		{"disabled", 0, 20 * time.Millisecond, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(Logger(tt.threshold))
+			router.GET("/products", func(c *gin.Context) {
+				time.Sleep(tt.delay)
+				c.Status(http.StatusOK)
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products", nil))
+
+			output := buf.String()
+			if !strings.Contains(output, "GET /products") {
+				t.Errorf("request was not logged: %q", output)
+			}
+			if gotWarn := strings.Contains(output, "WARN slow request"); gotWarn != tt.wantWarn {
+				t.Errorf("slow request warning = %v, want %v; log: %q", gotWarn, tt.wantWarn, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Maintenance rejects write requests with 503 while maintenance mode is on.
+// It can be toggled at runtime and is safe for concurrent use.
+type Maintenance struct {
+	enabled           atomic.Bool
+	retryAfterSeconds int
+	exemptPaths       []string
+}
+
+// NewMaintenance creates a new maintenance mode switch.
+// Requests whose route matches one of exemptPaths are always let through.
+func NewMaintenance(enabled bool, retryAfterSeconds int, exemptPaths ...string) *Maintenance {
+	m := &Maintenance{
+		retryAfterSeconds: retryAfterSeconds,
+		exemptPaths:       exemptPaths,
+	}
+	m.enabled.Store(enabled)
+	return m
+}
+
+// Enabled reports whether maintenance mode is on
+func (m *Maintenance) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// SetEnabled turns maintenance mode on or off
+func (m *Maintenance) SetEnabled(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// Middleware returns a Gin middleware function
+func (m *Maintenance) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.Enabled() || isReadOnlyMethod(c.Request.Method) || m.isExempt(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(m.retryAfterSeconds))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":       "service is under maintenance",
+			"retry_after": m.retryAfterSeconds,
+		})
+		c.Abort()
+	}
+}
+
+// isExempt reports whether a route is always let through
+func (m *Maintenance) isExempt(route string) bool {
+	for _, path := range m.exemptPaths {
+		if route == path {
+			return true
+		}
+	}
+	return false
+}
+
+// isReadOnlyMethod reports whether an HTTP method doesn't modify state
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := NewMaintenance(true, 120, "/api/v1/admin/maintenance")
+	router := gin.New()
+	router.Use(m.Middleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/products", ok)
+	router.HEAD("/api/v1/products", ok)
+	router.POST("/api/v1/cart", ok)
+	router.DELETE("/api/v1/cart/:id", ok)
+	router.POST("/api/v1/admin/maintenance", ok)
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"read allowed", true, http.MethodGet, "/api/v1/products", http.StatusOK},
+		{"head allowed", true, http.MethodHead, "/api/v1/products", http.StatusOK},
+		{"write blocked", true, http.MethodPost, "/api/v1/cart", http.StatusServiceUnavailable},
+		{"delete blocked", true, http.MethodDelete, "/api/v1/cart/1", http.StatusServiceUnavailable},
+		{"exempt write allowed", true, http.MethodPost, "/api/v1/admin/maintenance", http.StatusOK},
+		{"write allowed when off", false, http.MethodPost, "/api/v1/cart", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.SetEnabled(tt.enabled)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			wantRetryAfter := ""
+			if tt.wantStatus == http.StatusServiceUnavailable {
+				wantRetryAfter = "120"
+			}
+			if got := w.Header().Get("Retry-After"); got != wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, wantRetryAfter)
+			}
+		})
+	}
+}
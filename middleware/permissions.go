@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles maps user roles to the permissions they grant.
+// A permission is an area and an action, e.g. orders:write; * grants every permission
+// and orders:* every permission of an area.
+type Roles struct {
+	permissions map[string]map[string]bool
+}
+
+// NewRoles parses a role spec of semicolon-separated role=permissions entries with
+// comma-separated permissions, e.g. "admin=*;support=orders:read,users:read".
+// A permission written @role inherits every permission of that role, so
+// "superadmin=@admin,users:delete" extends admin.
+func NewRoles(spec string) (*Roles, error) {
+	grants := make(map[string][]string)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		role, list, ok := strings.Cut(entry, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("invalid role entry %q, expected role=permissions", entry)
+		}
+		if _, dup := grants[role]; dup {
+			return nil, fmt.Errorf("role %q is defined twice", role)
+		}
+		grants[role] = []string{}
+		for _, permission := range strings.Split(list, ",") {
+			if permission = strings.TrimSpace(permission); permission != "" {
+				grants[role] = append(grants[role], permission)
+			}
+		}
+	}
+
+	r := &Roles{permissions: make(map[string]map[string]bool, len(grants))}
+	for role := range grants {
+		if err := r.resolve(role, grants, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// resolve flattens a role's own and inherited permissions. visiting holds the roles being
+// resolved further up the inheritance chain, to catch cycles.
+func (r *Roles) resolve(role string, grants map[string][]string, visiting map[string]bool) error {
+	if _, done := r.permissions[role]; done {
+		return nil
+	}
+	if visiting[role] {
+		return fmt.Errorf("role %q inherits from itself", role)
+	}
+	visiting[role] = true
+
+	permissions := make(map[string]bool)
+	for _, permission := range grants[role] {
+		parent, inherits := strings.CutPrefix(permission, "@")
+		if !inherits {
+			permissions[permission] = true
+			continue
+		}
+		if _, ok := grants[parent]; !ok {
+			return fmt.Errorf("role %q inherits from unknown role %q", role, parent)
+		}
+		if err := r.resolve(parent, grants, visiting); err != nil {
+			return err
+		}
+		for inherited := range r.permissions[parent] {
+			permissions[inherited] = true
+		}
+	}
+
+	r.permissions[role] = permissions
+	return nil
+}
+
+// Defined reports whether the spec defines a role
+func (r *Roles) Defined(role string) bool {
+	_, ok := r.permissions[role]
+	return ok
+}
+
+// Has reports whether a role grants a permission, directly, through a wildcard or by inheritance
+func (r *Roles) Has(role, permission string) bool {
+	granted := r.permissions[role]
+	if granted["*"] || granted[permission] {
+		return true
+	}
+	area, _, _ := strings.Cut(permission, ":")
+	return granted[area+":*"]
+}
+
+// RequirePermission checks that the user's role grants every one of the permissions
+func (r *Roles) RequirePermission(permissions ...string) gin.HandlerFunc {
+	return r.require(func(role string) bool {
+		for _, permission := range permissions {
+			if !r.Has(role, permission) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// RequireAnyPermission checks that the user's role grants at least one of the permissions
+func (r *Roles) RequireAnyPermission(permissions ...string) gin.HandlerFunc {
+	return r.require(func(role string) bool {
+		for _, permission := range permissions {
+			if r.Has(role, permission) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// require builds a middleware that lets a request through when allowed accepts the user's role
+func (r *Roles) require(allowed func(role string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := GetUserFromContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "unauthorized",
+			})
+			c.Abort()
+			return
+		}
+
+		if !allowed(user.Role) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
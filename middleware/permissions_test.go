@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/models"
+)
+
+const testRoleSpec = "admin=*; support=orders:read,users:read; lead=@support,orders:write; catalog=catalog:*; user="
+
+func TestRolesHas(t *testing.T) {
+	roles, err := NewRoles(testRoleSpec)
+	if err != nil {
+		t.Fatalf("NewRoles() error = %v", err)
+	}
+
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{"admin", "maintenance:write", true},
+		{"support", "orders:read", true},
+		{"support", "orders:write", false},
+		// lead inherits support's permissions on top of its own
+		{"lead", "orders:read", true},
+		{"lead", "users:read", true},
+		{"lead", "orders:write", true},
+		{"lead", "users:write", false},
+		{"catalog", "catalog:write", true},
+		{"catalog", "orders:read", false},
+		{"user", "orders:read", false},
+		{"unknown", "orders:read", false},
+	}
+	for _, tt := range tests {
+		if got := roles.Has(tt.role, tt.permission); got != tt.want {
+			t.Errorf("Has(%s, %s) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+
+	for role, want := range map[string]bool{"admin": true, "lead": true, "user": true, "owner": false} {
+		if got := roles.Defined(role); got != want {
+			t.Errorf("Defined(%s) = %v, want %v", role, got, want)
+		}
+	}
+}
+
+func TestNewRolesRejectsInvalidSpecs(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{"admin", "invalid role entry"},
+		{"=orders:read", "invalid role entry"},
+		{"admin=*;admin=orders:read", "is defined twice"},
+		{"lead=@support", "inherits from unknown role"},
+		{"a=@b;b=@a", "inherits from itself"},
+		{"a=@a", "inherits from itself"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			if _, err := NewRoles(tt.spec); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewRoles(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	roles, err := NewRoles(testRoleSpec)
+	if err != nil {
+		t.Fatalf("NewRoles() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		role       string
+		required   gin.HandlerFunc
+		wantStatus int
+	}{
+		{"granted", "support", roles.RequirePermission("orders:read"), http.StatusOK},
+		{"inherited", "lead", roles.RequirePermission("users:read", "orders:write"), http.StatusOK},
+		{"denied", "support", roles.RequirePermission("orders:write"), http.StatusForbidden},
+		{"denied when one is missing", "support", roles.RequirePermission("orders:read", "orders:write"), http.StatusForbidden},
+		{"denied without permissions", "user", roles.RequirePermission("orders:read"), http.StatusForbidden},
+		{"any granted", "support", roles.RequireAnyPermission("orders:write", "users:read"), http.StatusOK},
+		{"any denied", "catalog", roles.RequireAnyPermission("orders:write", "users:read"), http.StatusForbidden},
+		{"no user", "", roles.RequirePermission("orders:read"), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.role != "" {
+					c.Set("user", &models.User{Role: tt.role})
+				}
+			})
+			router.GET("/admin", tt.required, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
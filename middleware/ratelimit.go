@@ -1,17 +1,29 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RateLimiter implements a simple token bucket rate limiter
+// Request classes the rate limiter budgets separately
+const (
+	RequestClassRead  = "read"
+	RequestClassWrite = "write"
+)
+
+// RateLimiter implements a simple token bucket rate limiter.
+// Reads (GET, HEAD and OPTIONS) and writes are counted in separate buckets per client.
 type RateLimiter struct {
-	requests      int
+	readRequests  int
+	writeRequests int
 	windowMinutes int
+	exemptPaths   []string
 	clients       map[string]*clientBucket
 	mu            sync.RWMutex
 }
@@ -21,11 +33,20 @@ type clientBucket struct {
 	lastReset time.Time
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(requests, windowMinutes int) *RateLimiter {
+// NewRateLimiter creates a new rate limiter that allows the same number of reads and writes.
+// Requests whose route matches one of exemptPaths are never limited;
+// a trailing "*" matches any route with that prefix.
+func NewRateLimiter(requests, windowMinutes int, exemptPaths ...string) *RateLimiter {
+	return NewMethodRateLimiter(requests, requests, windowMinutes, exemptPaths...)
+}
+
+// NewMethodRateLimiter creates a new rate limiter with separate budgets for reads and writes
+func NewMethodRateLimiter(readRequests, writeRequests, windowMinutes int, exemptPaths ...string) *RateLimiter {
 	limiter := &RateLimiter{
-		requests:      requests,
+		readRequests:  readRequests,
+		writeRequests: writeRequests,
 		windowMinutes: windowMinutes,
+		exemptPaths:   exemptPaths,
 		clients:       make(map[string]*clientBucket),
 	}
 
@@ -35,15 +56,30 @@ func NewRateLimiter(requests, windowMinutes int) *RateLimiter {
 	return limiter
 }
 
-// Middleware returns a Gin middleware function
+// Middleware returns a Gin middleware function.
+// Limited requests get X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers,
+// the last being the number of seconds until the caller's quota is refilled.
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
+		if rl.isExempt(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		class := RequestClass(c.Request.Method)
 
-		if !rl.allow(clientIP) {
+		allowed, remaining, reset := rl.allow(c.ClientIP(), class)
+		resetSeconds := int(math.Ceil(reset.Seconds()))
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rl.Limit(class)))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(resetSeconds))
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(resetSeconds))
 			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":   "rate limit exceeded",
-				"details": "too many requests, please try again later",
+				"error":       "rate limit exceeded",
+				"details":     "too many requests, please try again later",
+				"retry_after": resetSeconds,
 			})
 			c.Abort()
 			return
@@ -53,36 +89,104 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	}
 }
 
-// allow checks if a request is allowed
-func (rl *RateLimiter) allow(clientIP string) bool {
+// RequestClass returns the budget an HTTP method counts against
+func RequestClass(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RequestClassRead
+	default:
+		return RequestClassWrite
+	}
+}
+
+// Limit returns the number of requests of a class allowed per window
+func (rl *RateLimiter) Limit(class string) int {
+	if class == RequestClassWrite {
+		return rl.writeRequests
+	}
+	return rl.readRequests
+}
+
+// Status reports a client's remaining requests of a class and the time until its quota is refilled
+// without consuming a request
+func (rl *RateLimiter) Status(clientIP, class string) (int, time.Duration) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	limit := rl.Limit(class)
+	window := time.Duration(rl.windowMinutes) * time.Minute
+	bucket, exists := rl.clients[bucketKey(clientIP, class)]
+	if !exists {
+		return limit, window
+	}
+
+	elapsed := time.Since(bucket.lastReset)
+	if elapsed >= window {
+		return limit, window
+	}
+	return bucket.tokens, window - elapsed
+}
+
+// bucketKey identifies a client's bucket for a request class
+func bucketKey(clientIP, class string) string {
+	return class + ":" + clientIP
+}
+
+// isExempt checks if a route bypasses the limiter
+func (rl *RateLimiter) isExempt(route string) bool {
+	if route == "" {
+		return false
+	}
+	for _, pattern := range rl.exemptPaths {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(route, prefix) {
+				return true
+			}
+			continue
+		}
+		if route == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// allow checks if a request is allowed and consumes a request from the client's quota for its class.
+// It also returns the requests left and the time until the client's bucket resets.
+func (rl *RateLimiter) allow(clientIP, class string) (bool, int, time.Duration) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
 	now := time.Now()
-	bucket, exists := rl.clients[clientIP]
+	limit := rl.Limit(class)
+	window := time.Duration(rl.windowMinutes) * time.Minute
+	key := bucketKey(clientIP, class)
+	bucket, exists := rl.clients[key]
 
 	if !exists {
-		rl.clients[clientIP] = &clientBucket{
-			tokens:    rl.requests - 1,
+		rl.clients[key] = &clientBucket{
+			tokens:    limit - 1,
 			lastReset: now,
 		}
-		return true
+		return true, limit - 1, window
 	}
 
+	elapsed := now.Sub(bucket.lastReset)
+
 	// Reset bucket if window has passed
-	if now.Sub(bucket.lastReset) >= time.Duration(rl.windowMinutes)*time.Minute {
-		bucket.tokens = rl.requests - 1
+	if elapsed >= window {
+		bucket.tokens = limit - 1
 		bucket.lastReset = now
-		return true
+		return true, bucket.tokens, window
 	}
 
 	// Check if tokens available
 	if bucket.tokens > 0 {
 		bucket.tokens--
-		return true
+		return true, bucket.tokens, window - elapsed
 	}
 
-	return false
+	return false, 0, window - elapsed
 }
 
 // cleanup removes old client entries
@@ -93,9 +197,9 @@ func (rl *RateLimiter) cleanup() {
 	for range ticker.C {
 		rl.mu.Lock()
 		now := time.Now()
-		for clientIP, bucket := range rl.clients {
+		for key, bucket := range rl.clients {
 			if now.Sub(bucket.lastReset) >= time.Duration(rl.windowMinutes*2)*time.Minute {
-				delete(rl.clients, clientIP)
+				delete(rl.clients, key)
 			}
 		}
 		rl.mu.Unlock()
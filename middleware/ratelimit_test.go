@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRateLimitedRouter returns a test engine limited by rl with a few routes to hit
+func newRateLimitedRouter(rl *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(rl.Middleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/products", ok)
+	router.POST("/api/v1/cart", ok)
+	router.GET("/health", ok)
+	router.GET("/health/ready", ok)
+	router.GET("/metrics", ok)
+	return router
+}
+
+// doRequest sends a request from clientIP and returns the response
+func doRequest(router *gin.Engine, method, path, clientIP string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = clientIP + ":12345"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiterLimitsPerClient(t *testing.T) {
+	router := newRateLimitedRouter(NewRateLimiter(3, 1))
+
+	for i := 1; i <= 3; i++ {
+		if w := doRequest(router, http.MethodGet, "/api/v1/products", "10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i, w.Code)
+		}
+	}
+
+	w := doRequest(router, http.MethodGet, "/api/v1/products", "10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the limit status = %d, want 429", w.Code)
+	}
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Errorf("Retry-After = %q, want 1-60 seconds", w.Header().Get("Retry-After"))
+	}
+	var body struct {
+		RetryAfter int `json:"retry_after"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.RetryAfter != retryAfter {
+		t.Errorf("retry_after = %d, want %d to match the header", body.RetryAfter, retryAfter)
+	}
+
+	// Other clients have their own quota
+	if w := doRequest(router, http.MethodGet, "/api/v1/products", "10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("other client status = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimiterQuotaHeaders(t *testing.T) {
+	router := newRateLimitedRouter(NewRateLimiter(2, 1))
+
+	tests := []struct {
+		wantStatus    int
+		wantRemaining string
+	}{
+		{http.StatusOK, "1"},
+		{http.StatusOK, "0"},
+		{http.StatusTooManyRequests, "0"},
+	}
+	for i, tt := range tests {
+		w := doRequest(router, http.MethodGet, "/api/v1/products", "10.0.0.1")
+		if w.Code != tt.wantStatus {
+			t.Fatalf("request %d status = %d, want %d", i+1, w.Code, tt.wantStatus)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d X-RateLimit-Limit = %q, want 2", i+1, got)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+			t.Errorf("request %d X-RateLimit-Remaining = %q, want %s", i+1, got, tt.wantRemaining)
+		}
+		reset, err := strconv.Atoi(w.Header().Get("X-RateLimit-Reset"))
+		if err != nil || reset < 1 || reset > 60 {
+			t.Errorf("request %d X-RateLimit-Reset = %q, want 1-60 seconds", i+1, w.Header().Get("X-RateLimit-Reset"))
+		}
+	}
+}
+
+func TestRateLimiterExemptPaths(t *testing.T) {
+	router := newRateLimitedRouter(NewRateLimiter(1, 1, "/health*", "/metrics"))
+
+	tests := []struct {
+		path   string
+		exempt bool
+	}{
+		{"/health", true},
+		{"/health/ready", true},
+		{"/metrics", true},
+		{"/api/v1/products", false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// Each path is hit twice from a fresh client; only limited paths are refused the second time
+			clientIP := "10.1.0." + strconv.Itoa(i+1)
+			doRequest(router, http.MethodGet, tt.path, clientIP)
+			w := doRequest(router, http.MethodGet, tt.path, clientIP)
+
+			wantStatus := http.StatusTooManyRequests
+			if tt.exempt {
+				wantStatus = http.StatusOK
+			}
+			if w.Code != wantStatus {
+				t.Errorf("second request status = %d, want %d", w.Code, wantStatus)
+			}
+			if hasHeader := w.Header().Get("X-RateLimit-Limit") != ""; hasHeader == tt.exempt {
+				t.Errorf("X-RateLimit-Limit present = %v, want %v", hasHeader, !tt.exempt)
+			}
+		})
+	}
+}
+
+func TestRateLimiterIsExempt(t *testing.T) {
+	rl := NewRateLimiter(1, 1, "/health*", "/metrics")
+
+	tests := []struct {
+		route string
+		want  bool
+	}{
+		{"/health", true},
+		{"/health/live", true},
+		{"/metrics", true},
+		{"/metrics/extra", false},
+		{"/api/v1/health", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := rl.isExempt(tt.route); got != tt.want {
+			t.Errorf("isExempt(%q) = %v, want %v", tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterBudgetsReadsAndWritesSeparately(t *testing.T) {
+	router := newRateLimitedRouter(NewMethodRateLimiter(3, 1, 1))
+
+	// Using up the write budget doesn't touch the read budget
+	if w := doRequest(router, http.MethodPost, "/api/v1/cart", "10.0.0.1"); w.Code != http.StatusOK {
+		t.Fatalf("first write status = %d, want 200", w.Code)
+	}
+	w := doRequest(router, http.MethodPost, "/api/v1/cart", "10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second write status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "1" {
+		t.Errorf("write X-RateLimit-Limit = %q, want 1", got)
+	}
+
+	for i := 1; i <= 3; i++ {
+		w := doRequest(router, http.MethodGet, "/api/v1/products", "10.0.0.1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("read %d status = %d, want 200", i, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("read %d X-RateLimit-Limit = %q, want 3", i, got)
+		}
+	}
+	if w := doRequest(router, http.MethodGet, "/api/v1/products", "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("read over the limit status = %d, want 429", w.Code)
+	}
+}
+
+func TestRequestClass(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{http.MethodGet, RequestClassRead},
+		{http.MethodHead, RequestClassRead},
+		{http.MethodOptions, RequestClassRead},
+		{http.MethodPost, RequestClassWrite},
+		{http.MethodPut, RequestClassWrite},
+		{http.MethodPatch, RequestClassWrite},
+		{http.MethodDelete, RequestClassWrite},
+	}
+	for _, tt := range tests {
+		if got := RequestClass(tt.method); got != tt.want {
+			t.Errorf("RequestClass(%s) = %q, want %q", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterStatusDoesNotConsume(t *testing.T) {
+	rl := NewMethodRateLimiter(5, 2, 1)
+
+	if remaining, _ := rl.Status("10.0.0.1", RequestClassWrite); remaining != 2 {
+		t.Fatalf("fresh write remaining = %d, want 2", remaining)
+	}
+	rl.allow("10.0.0.1", RequestClassWrite)
+
+	for i := 0; i < 2; i++ {
+		if remaining, _ := rl.Status("10.0.0.1", RequestClassWrite); remaining != 1 {
+			t.Errorf("write remaining = %d, want 1", remaining)
+		}
+	}
+	if remaining, _ := rl.Status("10.0.0.1", RequestClassRead); remaining != 5 {
+		t.Errorf("read remaining = %d, want 5", remaining)
+	}
+}
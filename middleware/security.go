@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecureHeaders sets security headers on every response.
+// Strict-Transport-Security is omitted when hstsMaxAgeSeconds is 0 and
+// Content-Security-Policy is omitted when contentSecurityPolicy is empty.
+func SecureHeaders(hstsMaxAgeSeconds int, contentSecurityPolicy string) gin.HandlerFunc {
+	hsts := ""
+	if hstsMaxAgeSeconds > 0 {
+		hsts = "max-age=" + strconv.Itoa(hstsMaxAgeSeconds) + "; includeSubDomains"
+	}
+
+	return func(c *gin.Context) {
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("X-Frame-Options", "DENY")
+		if hsts != "" {
+			c.Header("Strict-Transport-Security", hsts)
+		}
+		if contentSecurityPolicy != "" {
+			c.Header("Content-Security-Policy", contentSecurityPolicy)
+		}
+		c.Next()
+	}
+}
+
+// HTTPSRedirect redirects plain HTTP requests to HTTPS.
+// Behind a TLS-terminating proxy the scheme is taken from X-Forwarded-Proto.
+// Paths matching exemptPaths (a trailing * matches a prefix) are served over HTTP,
+// so load balancer health checks keep working.
+func HTTPSRedirect(exemptPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSecureRequest(c.Request) || matchesPath(c.Request.URL.Path, exemptPaths) {
+			c.Next()
+			return
+		}
+
+		// 308 keeps the method and body of non-idempotent requests
+		status := http.StatusPermanentRedirect
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		c.Redirect(status, "https://"+c.Request.Host+c.Request.URL.RequestURI())
+		c.Abort()
+	}
+}
+
+// isSecureRequest reports whether a request reached the client over HTTPS
+func isSecureRequest(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// matchesPath reports whether a path matches one of the patterns.
+// A trailing * matches any path with that prefix.
+func matchesPath(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+			continue
+		}
+		if path == pattern {
+			return true
+		}
+	}
+	return false
+}
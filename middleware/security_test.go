@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		hsts     int
+		csp      string
+		wantHSTS string
+		wantCSP  string
+	}{
+		{"all headers", 31536000, "default-src 'none'", "max-age=31536000; includeSubDomains", "default-src 'none'"},
+		{"hsts and csp disabled", 0, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(SecureHeaders(tt.hsts, tt.csp))
+			router.GET("/products", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products", nil))
+
+			want := map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Strict-Transport-Security": tt.wantHSTS,
+				"Content-Security-Policy":   tt.wantCSP,
+			}
+			for header, value := range want {
+				if got := w.Header().Get(header); got != value {
+					t.Errorf("%s = %q, want %q", header, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPSRedirect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(HTTPSRedirect("/health*"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/products", ok)
+	router.POST("/orders", ok)
+	router.GET("/health/ready", ok)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		forwarded    string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"plain GET", http.MethodGet, "/products?page=2", "", http.StatusMovedPermanently, "https://shop.example.com/products?page=2"},
+		{"plain POST keeps method", http.MethodPost, "/orders", "", http.StatusPermanentRedirect, "https://shop.example.com/orders"},
+		{"forwarded HTTPS", http.MethodGet, "/products", "https", http.StatusOK, ""},
+		{"exempt health check", http.MethodGet, "/health/ready", "", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://shop.example.com"+tt.path, nil)
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.forwarded)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
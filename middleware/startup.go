@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireStarted answers 503 until started reports true, so requests that arrive while the
+// server is still starting up don't reach handlers. Paths matching exemptPaths, where a
+// trailing * matches any path with that prefix, are always let through.
+func RequireStarted(started func() bool, exemptPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if started() || matchesPath(c.Request.URL.Path, exemptPaths) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "server is starting",
+		})
+		c.Abort()
+	}
+}
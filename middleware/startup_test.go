@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireStarted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var started atomic.Bool
+	router := gin.New()
+	router.Use(RequireStarted(started.Load, "/health*"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/health", ok)
+	router.GET("/health/ready", ok)
+	router.GET("/version", ok)
+	router.POST("/api/v1/cart", ok)
+
+	tests := []struct {
+		name       string
+		started    bool
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"liveness while starting", false, http.MethodGet, "/health", http.StatusOK},
+		{"readiness while starting", false, http.MethodGet, "/health/ready", http.StatusOK},
+		{"read while starting", false, http.MethodGet, "/version", http.StatusServiceUnavailable},
+		{"write while starting", false, http.MethodPost, "/api/v1/cart", http.StatusServiceUnavailable},
+		{"unknown path while starting", false, http.MethodGet, "/nope", http.StatusServiceUnavailable},
+		{"read once started", true, http.MethodGet, "/version", http.StatusOK},
+		{"write once started", true, http.MethodPost, "/api/v1/cart", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started.Store(tt.started)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
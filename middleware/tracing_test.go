@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingStartsSpanPerRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Tracing())
+	router.GET("/products/:id", func(c *gin.Context) {
+		if !trace.SpanFromContext(c.Request.Context()).SpanContext().IsValid() {
+			t.Error("handler context carries no span")
+		}
+		c.Status(http.StatusOK)
+	})
+	router.POST("/orders", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	tests := []struct {
+		method     string
+		path       string
+		wantName   string
+		wantRoute  string
+		wantStatus int
+		wantError  bool
+	}{
+		{http.MethodGet, "/products/42", "GET /products/:id", "/products/:id", http.StatusOK, false},
+		{http.MethodPost, "/orders", "POST /orders", "/orders", http.StatusInternalServerError, true},
+		{http.MethodGet, "/missing", "GET /missing", "/missing", http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != len(tests) {
+		t.Fatalf("ended spans = %d, want %d", len(spans), len(tests))
+	}
+	for i, tt := range tests {
+		span := spans[i]
+		t.Run(tt.wantName, func(t *testing.T) {
+			if span.Name() != tt.wantName {
+				t.Errorf("name = %q, want %q", span.Name(), tt.wantName)
+			}
+			if span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("kind = %v, want server", span.SpanKind())
+			}
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, kv := range span.Attributes() {
+				attrs[kv.Key] = kv.Value
+			}
+			if got := attrs[semconv.HTTPRouteKey].AsString(); got != tt.wantRoute {
+				t.Errorf("http.route = %q, want %q", got, tt.wantRoute)
+			}
+			if got := attrs[semconv.HTTPResponseStatusCodeKey].AsInt64(); got != int64(tt.wantStatus) {
+				t.Errorf("status code = %d, want %d", got, tt.wantStatus)
+			}
+			if gotError := span.Status().Code == codes.Error; gotError != tt.wantError {
+				t.Errorf("error status = %v, want %v", gotError, tt.wantError)
+			}
+		})
+	}
+}
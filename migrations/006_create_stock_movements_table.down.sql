@@ -0,0 +1,2 @@
+-- Drop stock_movements table
+DROP TABLE IF EXISTS stock_movements CASCADE;
@@ -0,0 +1,2 @@
+-- Drop addresses table
+DROP TABLE IF EXISTS addresses CASCADE;
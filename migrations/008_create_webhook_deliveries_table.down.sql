@@ -0,0 +1,2 @@
+-- Drop webhook_deliveries table
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
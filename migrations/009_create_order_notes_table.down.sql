@@ -0,0 +1,2 @@
+-- Drop order_notes table
+DROP TABLE IF EXISTS order_notes CASCADE;
@@ -0,0 +1,3 @@
+-- Drop review tables
+DROP TABLE IF EXISTS review_votes CASCADE;
+DROP TABLE IF EXISTS reviews CASCADE;
@@ -0,0 +1,2 @@
+-- Drop price_cents_at_add from cart_items
+ALTER TABLE cart_items DROP COLUMN IF EXISTS price_cents_at_add;
@@ -0,0 +1,8 @@
+-- Add price_cents_at_add to cart_items
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS price_cents_at_add INTEGER NOT NULL DEFAULT 0;
+
+-- Backfill existing items with the current product price
+UPDATE cart_items
+SET price_cents_at_add = products.price_cents
+FROM products
+WHERE products.id = cart_items.product_id AND cart_items.price_cents_at_add = 0;
@@ -0,0 +1,5 @@
+-- Add deleted_at to users for soft deletes
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Create index on deleted_at
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
@@ -0,0 +1,2 @@
+-- Drop back_in_stock_subscriptions table
+DROP TABLE IF EXISTS back_in_stock_subscriptions;
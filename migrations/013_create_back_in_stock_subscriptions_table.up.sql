@@ -0,0 +1,12 @@
+-- Create back_in_stock_subscriptions table
+CREATE TABLE IF NOT EXISTS back_in_stock_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    notified BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- One pending subscription per user and product; notified rows are kept as history
+CREATE UNIQUE INDEX IF NOT EXISTS idx_back_in_stock_pending ON back_in_stock_subscriptions(product_id, user_id) WHERE NOT notified;
@@ -0,0 +1,3 @@
+-- Drop refund tables
+DROP TABLE IF EXISTS refund_items CASCADE;
+DROP TABLE IF EXISTS refunds CASCADE;
@@ -0,0 +1,3 @@
+-- Drop username from users
+DROP INDEX IF EXISTS idx_users_username;
+ALTER TABLE users DROP COLUMN IF EXISTS username;
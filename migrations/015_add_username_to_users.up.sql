@@ -0,0 +1,5 @@
+-- Add optional username to users for logging in without an email address
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username VARCHAR(30);
+
+-- Create unique index on username (NULLs don't conflict)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(username);
@@ -0,0 +1,3 @@
+-- Drop unit_type from order_items and products
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_type;
+ALTER TABLE products DROP COLUMN IF EXISTS unit_type;
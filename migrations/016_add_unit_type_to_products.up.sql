@@ -0,0 +1,7 @@
+-- Add unit_type to products; weight products are priced per kilogram with stock in grams
+ALTER TABLE products ADD COLUMN IF NOT EXISTS unit_type VARCHAR(10) NOT NULL DEFAULT 'each'
+    CHECK (unit_type IN ('each', 'weight'));
+
+-- Snapshot the unit type on order items so past orders keep their pricing
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_type VARCHAR(10) NOT NULL DEFAULT 'each'
+    CHECK (unit_type IN ('each', 'weight'));
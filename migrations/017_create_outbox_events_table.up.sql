@@ -0,0 +1,15 @@
+-- Create outbox_events table (webhook events written with the change they describe)
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    request_id VARCHAR(100),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_outbox_events_sent_at ON outbox_events(sent_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unsent ON outbox_events(created_at) WHERE sent_at IS NULL;
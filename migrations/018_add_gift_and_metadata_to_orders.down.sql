@@ -0,0 +1,4 @@
+-- Drop gift options and metadata from orders
+ALTER TABLE orders DROP COLUMN IF EXISTS metadata;
+ALTER TABLE orders DROP COLUMN IF EXISTS gift_message;
+ALTER TABLE orders DROP COLUMN IF EXISTS is_gift;
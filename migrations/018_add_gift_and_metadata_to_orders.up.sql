@@ -0,0 +1,4 @@
+-- Add gift options and free-form metadata to orders
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS is_gift BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS gift_message TEXT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS metadata JSONB;
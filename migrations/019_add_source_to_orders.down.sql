@@ -0,0 +1,3 @@
+-- Drop source from orders
+DROP INDEX IF EXISTS idx_orders_source;
+ALTER TABLE orders DROP COLUMN IF EXISTS source;
@@ -0,0 +1,4 @@
+-- Record the channel each order was placed through; existing orders are attributed to web
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS source VARCHAR(10) NOT NULL DEFAULT 'web'
+    CHECK (source IN ('web', 'mobile', 'api'));
+CREATE INDEX IF NOT EXISTS idx_orders_source ON orders(source);
@@ -0,0 +1,2 @@
+-- Drop the trigram index; the pg_trgm extension is left in place as other objects may use it
+DROP INDEX IF EXISTS idx_products_name_trgm;
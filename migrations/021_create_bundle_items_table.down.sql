@@ -0,0 +1,3 @@
+-- Drop bundle_items table and the bundle flag
+DROP TABLE IF EXISTS bundle_items;
+ALTER TABLE products DROP COLUMN IF EXISTS is_bundle;
@@ -0,0 +1,15 @@
+-- Flag bundle products; a bundle's stock is derived from its components
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_bundle BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Create bundle_items table
+CREATE TABLE IF NOT EXISTS bundle_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bundle_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    component_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bundle_items_bundle_component ON bundle_items(bundle_id, component_id);
+CREATE INDEX IF NOT EXISTS idx_bundle_items_component_id ON bundle_items(component_id);
@@ -0,0 +1,3 @@
+-- Drop status from products
+DROP INDEX IF EXISTS idx_products_status;
+ALTER TABLE products DROP COLUMN IF EXISTS status;
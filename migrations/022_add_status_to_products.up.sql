@@ -0,0 +1,5 @@
+-- Stage products as drafts before they go live; existing products stay published
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'published', 'archived'));
+ALTER TABLE products ALTER COLUMN status SET DEFAULT 'draft';
+CREATE INDEX IF NOT EXISTS idx_products_status ON products(status);
@@ -0,0 +1,2 @@
+-- Drop price_tiers table
+DROP TABLE IF EXISTS price_tiers;
@@ -0,0 +1,13 @@
+-- Create price_tiers table for volume discounts
+CREATE TABLE IF NOT EXISTS price_tiers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    min_quantity INTEGER NOT NULL CHECK (min_quantity > 1),
+    price_cents INTEGER CHECK (price_cents >= 0),
+    percent_off INTEGER CHECK (percent_off BETWEEN 1 AND 99),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK ((price_cents IS NULL) <> (percent_off IS NULL))
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_tiers_product_min_quantity ON price_tiers(product_id, min_quantity);
@@ -0,0 +1,2 @@
+-- Drop stock_holds table
+DROP TABLE IF EXISTS stock_holds;
@@ -0,0 +1,13 @@
+-- Create stock_holds table; checkout previews hold stock for a few minutes
+CREATE TABLE IF NOT EXISTS stock_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_holds_user_product ON stock_holds(user_id, product_id);
+CREATE INDEX IF NOT EXISTS idx_stock_holds_product_expires ON stock_holds(product_id, expires_at);
@@ -0,0 +1,2 @@
+-- Drop order_adjustments table
+DROP TABLE IF EXISTS order_adjustments;
@@ -0,0 +1,13 @@
+-- Create order_adjustments table for manual changes to order totals
+CREATE TABLE IF NOT EXISTS order_adjustments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    amount_cents INTEGER NOT NULL CHECK (amount_cents <> 0),
+    currency VARCHAR(3) NOT NULL,
+    reason TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_order_adjustments_order_id ON order_adjustments(order_id);
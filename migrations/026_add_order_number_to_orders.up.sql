@@ -0,0 +1,11 @@
+-- Give orders a sequential, human-friendly number; existing orders are numbered in creation order
+CREATE SEQUENCE IF NOT EXISTS order_number_seq;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS order_number VARCHAR(32);
+UPDATE orders o
+SET order_number = 'ORD-' || EXTRACT(YEAR FROM n.created_at)::int || '-' || LPAD(n.seq::text, 6, '0')
+FROM (
+    SELECT id, created_at, nextval('order_number_seq') AS seq
+    FROM (SELECT id, created_at FROM orders WHERE order_number IS NULL ORDER BY created_at, id) ordered
+) n
+WHERE o.id = n.id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_order_number ON orders(order_number);
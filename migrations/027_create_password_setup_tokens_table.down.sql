@@ -0,0 +1,2 @@
+-- Drop password_setup_tokens table
+DROP TABLE IF EXISTS password_setup_tokens;
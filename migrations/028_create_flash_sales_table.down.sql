@@ -0,0 +1,5 @@
+-- Drop flash_sales tables
+DROP INDEX IF EXISTS idx_order_items_flash_sale_id;
+ALTER TABLE order_items DROP COLUMN IF EXISTS flash_sale_id;
+DROP TABLE IF EXISTS flash_sale_products;
+DROP TABLE IF EXISTS flash_sales;
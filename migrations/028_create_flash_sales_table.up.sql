@@ -0,0 +1,26 @@
+-- Create flash_sales tables; sale prices apply between starts_at and ends_at
+CREATE TABLE IF NOT EXISTS flash_sales (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(200) NOT NULL,
+    percent_off INTEGER NOT NULL CHECK (percent_off BETWEEN 1 AND 99),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    per_user_limit INTEGER CHECK (per_user_limit > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE TABLE IF NOT EXISTS flash_sale_products (
+    flash_sale_id UUID NOT NULL REFERENCES flash_sales(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    PRIMARY KEY (flash_sale_id, product_id)
+);
+
+-- Record which flash sale an order item was bought in, for per-user limits
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS flash_sale_id UUID REFERENCES flash_sales(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_flash_sales_window ON flash_sales(starts_at, ends_at);
+CREATE INDEX IF NOT EXISTS idx_flash_sale_products_product_id ON flash_sale_products(product_id);
+CREATE INDEX IF NOT EXISTS idx_order_items_flash_sale_id ON order_items(flash_sale_id);
@@ -0,0 +1,2 @@
+-- Drop order_discounts table
+DROP TABLE IF EXISTS order_discounts;
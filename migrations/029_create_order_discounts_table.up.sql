@@ -0,0 +1,15 @@
+-- Create order_discounts table for one-off discounts granted on pending orders
+CREATE TABLE IF NOT EXISTS order_discounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('percent', 'fixed')),
+    value INTEGER NOT NULL CHECK (value > 0),
+    amount_cents INTEGER NOT NULL CHECK (amount_cents >= 0),
+    currency VARCHAR(3) NOT NULL,
+    reason TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_order_discounts_order_id ON order_discounts(order_id);
@@ -0,0 +1,3 @@
+-- Remove tags from products
+DROP INDEX IF EXISTS idx_products_tags;
+ALTER TABLE products DROP COLUMN IF EXISTS tags;
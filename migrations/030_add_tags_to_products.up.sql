@@ -0,0 +1,5 @@
+-- Add free-form merchandising tags to products
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tags JSONB;
+
+-- Create indexes; the GIN index serves tag containment (@>) filters
+CREATE INDEX IF NOT EXISTS idx_products_tags ON products USING GIN (tags);
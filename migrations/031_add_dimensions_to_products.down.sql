@@ -0,0 +1,5 @@
+-- Remove shipping weight and dimensions from products
+ALTER TABLE products DROP COLUMN IF EXISTS height_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS width_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS length_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS weight_grams;
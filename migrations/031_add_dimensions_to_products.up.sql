@@ -0,0 +1,5 @@
+-- Add shipping weight and dimensions of one packed unit to products; 0 means unknown
+ALTER TABLE products ADD COLUMN IF NOT EXISTS weight_grams INTEGER NOT NULL DEFAULT 0 CHECK (weight_grams >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS length_mm INTEGER NOT NULL DEFAULT 0 CHECK (length_mm >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS width_mm INTEGER NOT NULL DEFAULT 0 CHECK (width_mm >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS height_mm INTEGER NOT NULL DEFAULT 0 CHECK (height_mm >= 0);
@@ -0,0 +1,3 @@
+-- Remove the featured rank from products
+DROP INDEX IF EXISTS idx_products_featured_rank;
+ALTER TABLE products DROP COLUMN IF EXISTS featured_rank;
@@ -0,0 +1,3 @@
+-- Add the curated featured rank to products; NULL means not featured
+ALTER TABLE products ADD COLUMN IF NOT EXISTS featured_rank INTEGER CHECK (featured_rank >= 1);
+CREATE INDEX IF NOT EXISTS idx_products_featured_rank ON products(featured_rank);
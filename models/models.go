@@ -9,13 +9,15 @@ import (
 
 // User represents a user account
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
-	Email        string    `gorm:"uniqueIndex;not null" json:"email"`
-	PasswordHash string    `gorm:"not null" json:"-"`
-	FullName     string    `json:"full_name"`
-	Role         string    `gorm:"not null;default:'user'" json:"role"` // user, admin
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	Email        string         `gorm:"uniqueIndex;not null" json:"email"`
+	Username     *string        `gorm:"uniqueIndex" json:"username,omitempty"`
+	PasswordHash string         `gorm:"not null" json:"-"`
+	FullName     string         `json:"full_name"`
+	Role         string         `gorm:"not null;default:'user'" json:"role"` // user, admin
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 // BeforeCreate hook to generate UUID before creating
@@ -26,18 +28,92 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// Product represents a product in the catalog
+// Address represents a saved shipping address
+type Address struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Label     string    `json:"label"`
+	Line1     string    `gorm:"not null" json:"line1"`
+	Line2     string    `json:"line2"`
+	City      string    `gorm:"not null" json:"city"`
+	State     string    `json:"state"`
+	Country   string    `gorm:"not null" json:"country"`
+	Postcode  string    `gorm:"not null" json:"postcode"`
+	IsDefault bool      `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (a *Address) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// Snapshot returns a copy of the address suitable for storing on an order
+func (a *Address) Snapshot() JSONMap {
+	return JSONMap{
+		"line1":    a.Line1,
+		"line2":    a.Line2,
+		"city":     a.City,
+		"state":    a.State,
+		"country":  a.Country,
+		"postcode": a.Postcode,
+	}
+}
+
+// Product unit types.
+// Weight products are priced per kilogram and their stock and quantities are in grams.
+const (
+	UnitTypeEach   = "each"
+	UnitTypeWeight = "weight"
+)
+
+// Product statuses.
+// Only published products are shown in the public catalog and can be added to carts.
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusPublished = "published"
+	ProductStatusArchived  = "archived"
+)
+
+// GramsPerKilogram converts weight quantities to the unit weight products are priced in
+const GramsPerKilogram = 1000
+
+// Product represents a product in the catalog.
+// A bundle is sold as one product but made of BundleItems; its stock is the number of
+// complete bundles its components' stock allows and is kept up to date by the stock helpers.
 type Product struct {
-	ID          uuid.UUID       `gorm:"type:uuid;primary_key;" json:"id"`
-	SKU         string          `gorm:"uniqueIndex;not null" json:"sku"`
-	Name        string          `gorm:"not null" json:"name"`
-	Description string          `json:"description"`
-	PriceCents  int             `gorm:"not null" json:"price_cents"`
-	Currency    string          `gorm:"not null;default:'USD'" json:"currency"`
-	Stock       int             `gorm:"not null;default:0" json:"stock"`
-	Images      JSONStringSlice `gorm:"type:jsonb" json:"images"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
+	ID           uuid.UUID       `gorm:"type:uuid;primary_key;" json:"id"`
+	SKU          string          `gorm:"uniqueIndex;not null" json:"sku"`
+	Name         string          `gorm:"not null" json:"name"`
+	Description  string          `json:"description"`
+	PriceCents   int             `gorm:"not null" json:"price_cents"`
+	Currency     string          `gorm:"not null;default:'USD'" json:"currency"`
+	Stock        int             `gorm:"not null;default:0" json:"stock"`
+	UnitType     string          `gorm:"not null;default:'each'" json:"unit_type"` // each, weight
+	WeightGrams  int             `gorm:"not null;default:0" json:"weight_grams"`   // of one packed unit, like the dimensions; 0 means unknown
+	LengthMm     int             `gorm:"not null;default:0" json:"length_mm"`
+	WidthMm      int             `gorm:"not null;default:0" json:"width_mm"`
+	HeightMm     int             `gorm:"not null;default:0" json:"height_mm"`
+	Images       JSONStringSlice `gorm:"type:jsonb" json:"images"`
+	Tags         JSONStringSlice `gorm:"type:jsonb;index:idx_products_tags,type:gin" json:"tags"` // lowercase, e.g. sale, new, vegan
+	IsBundle     bool            `gorm:"not null;default:false" json:"is_bundle"`
+	Status       string          `gorm:"not null;default:'draft';index" json:"status"` // draft, published, archived
+	FeaturedRank *int            `gorm:"index" json:"featured_rank"`                   // curated position in the featured sort, lowest first; nil when not featured
+	BundleItems  []BundleItem    `gorm:"foreignKey:BundleID" json:"bundle_items,omitempty"`
+	PriceTiers   []PriceTier     `gorm:"foreignKey:ProductID" json:"price_tiers,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+
+	// PriceFormatted is the display price, only filled in on request
+	PriceFormatted string `gorm:"-" json:"price_formatted,omitempty"`
+	// AvailableStock is stock minus active checkout holds, only filled in by catalog queries that select it
+	AvailableStock *int `gorm:"->;-:migration" json:"available_stock,omitempty"`
+	// FlashSale is the product's running flash sale, only filled in where prices are shown or charged
+	FlashSale *ProductFlashSale `gorm:"-" json:"flash_sale,omitempty"`
 }
 
 // BeforeCreate hook to generate UUID before creating
@@ -48,16 +124,208 @@ func (p *Product) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// CartItem represents an item in a user's shopping cart
-type CartItem struct {
+// Price returns the product's unit price
+func (p *Product) Price() Money {
+	return NewMoney(int64(p.PriceCents), p.Currency)
+}
+
+// FormatPrices fills in the product's display price
+func (p *Product) FormatPrices() {
+	p.PriceFormatted = p.Price().Format()
+}
+
+// PackageWeightGrams returns the shipping weight of quantity units of the product.
+// The quantity of a product sold by weight is its weight in grams.
+func (p *Product) PackageWeightGrams(quantity int) int {
+	if p.UnitType == UnitTypeWeight {
+		return quantity
+	}
+	return p.WeightGrams * quantity
+}
+
+// PackageVolumeMm3 returns the packed volume of quantity units of the product in cubic millimetres.
+// Products sold by weight have no unit dimensions and count as 0.
+func (p *Product) PackageVolumeMm3(quantity int) int64 {
+	if p.UnitType == UnitTypeWeight {
+		return 0
+	}
+	return int64(p.LengthMm) * int64(p.WidthMm) * int64(p.HeightMm) * int64(quantity)
+}
+
+// UnitPriceCents returns the unit price for buying quantity units of the product:
+// the lowest price among the base price, the tiers the quantity qualifies for and the flash sale price.
+// PriceTiers and FlashSale must be loaded for their prices to apply.
+func (p *Product) UnitPriceCents(quantity int) int {
+	best := p.tierUnitPriceCents(quantity)
+	if p.FlashSale != nil && p.FlashSale.SalePriceCents < best {
+		best = p.FlashSale.SalePriceCents
+	}
+	return best
+}
+
+// OnFlashSale reports whether buying quantity units of the product is priced by its flash sale
+func (p *Product) OnFlashSale(quantity int) bool {
+	return p.FlashSale != nil && p.FlashSale.SalePriceCents < p.tierUnitPriceCents(quantity)
+}
+
+// tierUnitPriceCents returns the lowest price among the base price and the tiers the quantity qualifies for
+func (p *Product) tierUnitPriceCents(quantity int) int {
+	best := p.PriceCents
+	for _, tier := range p.PriceTiers {
+		if quantity < tier.MinQuantity {
+			continue
+		}
+		if price := tier.unitPriceCents(p.PriceCents); price < best {
+			best = price
+		}
+	}
+	return best
+}
+
+// LineTotal returns the price of quantity units of the product, including volume discounts
+func (p *Product) LineTotal(quantity int) Money {
+	return NewMoney(LineTotalCents(p.UnitPriceCents(quantity), quantity, p.UnitType), p.Currency)
+}
+
+// LineTotalCents prices a quantity at a unit price.
+// For weight products the quantity is in grams and the price is per kilogram; the result is
+// rounded half up to the nearest cent.
+func LineTotalCents(priceCents, quantity int, unitType string) int64 {
+	if unitType == UnitTypeWeight {
+		return (int64(priceCents)*int64(quantity) + GramsPerKilogram/2) / GramsPerKilogram
+	}
+	return int64(priceCents) * int64(quantity)
+}
+
+// BundleItem is a component of a bundle product and the quantity of it each bundle contains
+type BundleItem struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	BundleID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_bundle_items_bundle_component" json:"bundle_id"`
+	ComponentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_bundle_items_bundle_component;index" json:"component_id"`
+	Component   *Product  `gorm:"foreignKey:ComponentID" json:"component,omitempty"`
+	Quantity    int       `gorm:"not null" json:"quantity"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (bi *BundleItem) BeforeCreate(tx *gorm.DB) error {
+	if bi.ID == uuid.Nil {
+		bi.ID = uuid.New()
+	}
+	return nil
+}
+
+// PriceTier is a volume discount: buying at least MinQuantity units of the product lowers
+// its unit price to PriceCents, or by PercentOff percent. Exactly one of the two is set.
+// MinQuantity is in grams for products sold by weight.
+type PriceTier struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ProductID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_price_tiers_product_min_quantity" json:"product_id"`
+	MinQuantity int       `gorm:"not null;uniqueIndex:idx_price_tiers_product_min_quantity" json:"min_quantity"`
+	PriceCents  *int      `json:"price_cents,omitempty"`
+	PercentOff  *int      `json:"percent_off,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (pt *PriceTier) BeforeCreate(tx *gorm.DB) error {
+	if pt.ID == uuid.Nil {
+		pt.ID = uuid.New()
+	}
+	return nil
+}
+
+// unitPriceCents returns the tier's unit price for a product with the given base price.
+// Percentage discounts are rounded half up to the nearest cent.
+func (pt *PriceTier) unitPriceCents(basePriceCents int) int {
+	if pt.PriceCents != nil {
+		return *pt.PriceCents
+	}
+	if pt.PercentOff != nil {
+		return int((int64(basePriceCents)*int64(100-*pt.PercentOff) + 50) / 100)
+	}
+	return basePriceCents
+}
+
+// FlashSale discounts a set of products by a percentage between StartsAt and EndsAt.
+// Sale prices apply and expire on their own; nothing changes the products themselves.
+// PerUserLimit caps the units of each product a user can buy at the sale price.
+type FlashSale struct {
+	ID           uuid.UUID          `gorm:"type:uuid;primary_key;" json:"id"`
+	Name         string             `gorm:"not null" json:"name"`
+	PercentOff   int                `gorm:"not null" json:"percent_off"`
+	StartsAt     time.Time          `gorm:"not null;index:idx_flash_sales_window" json:"starts_at"`
+	EndsAt       time.Time          `gorm:"not null;index:idx_flash_sales_window" json:"ends_at"`
+	PerUserLimit *int               `json:"per_user_limit,omitempty"`
+	Products     []FlashSaleProduct `gorm:"foreignKey:FlashSaleID" json:"products,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (fs *FlashSale) BeforeCreate(tx *gorm.DB) error {
+	if fs.ID == uuid.Nil {
+		fs.ID = uuid.New()
+	}
+	return nil
+}
+
+// SalePriceCents returns the sale price of a product with the given base price, rounded half up to the nearest cent
+func (fs *FlashSale) SalePriceCents(basePriceCents int) int {
+	return int((int64(basePriceCents)*int64(100-fs.PercentOff) + 50) / 100)
+}
+
+// FlashSaleProduct puts a product in a flash sale
+type FlashSaleProduct struct {
+	FlashSaleID uuid.UUID `gorm:"type:uuid;primary_key" json:"flash_sale_id"`
+	ProductID   uuid.UUID `gorm:"type:uuid;primary_key;index" json:"product_id"`
+}
+
+// ProductFlashSale is a running flash sale as it applies to one product
+type ProductFlashSale struct {
+	ID             uuid.UUID `json:"id"`
+	PercentOff     int       `json:"percent_off"`
+	SalePriceCents int       `json:"sale_price_cents"`
+	EndsAt         time.Time `json:"ends_at"`
+	PerUserLimit   *int      `json:"per_user_limit,omitempty"`
+}
+
+// AllowsPurchase reports whether a user who has bought bought units at the sale price
+// can buy quantity more without going past the per-user limit
+func (s *ProductFlashSale) AllowsPurchase(bought, quantity int) bool {
+	return s.PerUserLimit == nil || bought+quantity <= *s.PerUserLimit
+}
+
+// StockHold sets aside stock for a user who is checking out, so other shoppers can't buy it
+// until the hold expires or the user places the order. A user has at most one hold per product.
+type StockHold struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null;index:idx_cart_user_product" json:"user_id"`
-	User      *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	ProductID uuid.UUID `gorm:"type:uuid;not null;index:idx_cart_user_product" json:"product_id"`
-	Product   *Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_stock_holds_user_product" json:"user_id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_stock_holds_user_product;index:idx_stock_holds_product_expires" json:"product_id"`
 	Quantity  int       `gorm:"not null" json:"quantity"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_stock_holds_product_expires" json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (sh *StockHold) BeforeCreate(tx *gorm.DB) error {
+	if sh.ID == uuid.Nil {
+		sh.ID = uuid.New()
+	}
+	return nil
+}
+
+// CartItem represents an item in a user's shopping cart
+type CartItem struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID          uuid.UUID `gorm:"type:uuid;not null;index:idx_cart_user_product" json:"user_id"`
+	User            *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ProductID       uuid.UUID `gorm:"type:uuid;not null;index:idx_cart_user_product" json:"product_id"`
+	Product         *Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Quantity        int       `gorm:"not null" json:"quantity"`
+	PriceCentsAtAdd int       `gorm:"not null;default:0" json:"price_cents_at_add"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 // BeforeCreate hook to generate UUID before creating
@@ -68,19 +336,55 @@ func (c *CartItem) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// Order statuses
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusCancelled = "cancelled"
+)
+
+// Order sources, the channel an order was placed through
+const (
+	OrderSourceWeb    = "web"
+	OrderSourceMobile = "mobile"
+	OrderSourceAPI    = "api"
+)
+
+// OrderSources lists the accepted order sources
+var OrderSources = []string{OrderSourceWeb, OrderSourceMobile, OrderSourceAPI}
+
+// IsValidOrderSource reports whether source is one of OrderSources
+func IsValidOrderSource(source string) bool {
+	for _, s := range OrderSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
 // Order represents a customer order
 type Order struct {
 	ID              uuid.UUID   `gorm:"type:uuid;primary_key;" json:"id"`
+	OrderNumber     string      `gorm:"size:32;uniqueIndex:idx_orders_order_number" json:"order_number"` // e.g. ORD-2024-000123
 	UserID          uuid.UUID   `gorm:"type:uuid;not null;index" json:"user_id"`
 	User            *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	TotalCents      int         `gorm:"not null" json:"total_cents"`
 	Currency        string      `gorm:"not null" json:"currency"`
-	Status          string      `gorm:"not null;default:'pending'" json:"status"` // pending, paid, shipped, cancelled
+	Status          string      `gorm:"not null;default:'pending'" json:"status"`   // pending, paid, shipped, cancelled
+	Source          string      `gorm:"not null;default:'web';index" json:"source"` // web, mobile, api
 	ShippingAddress JSONMap     `gorm:"type:jsonb" json:"shipping_address"`
 	PaymentInfo     JSONMap     `gorm:"type:jsonb" json:"payment_info,omitempty"`
+	IsGift          bool        `gorm:"not null;default:false" json:"is_gift"`
+	GiftMessage     string      `gorm:"type:text" json:"gift_message,omitempty"`
+	Metadata        JSONMap     `gorm:"type:jsonb" json:"metadata,omitempty"`
 	Items           []OrderItem `gorm:"foreignKey:OrderID" json:"items,omitempty"`
 	CreatedAt       time.Time   `json:"created_at"`
 	UpdatedAt       time.Time   `json:"updated_at"`
+
+	// TotalFormatted is the display total, only filled in on request
+	TotalFormatted string `gorm:"-" json:"total_formatted,omitempty"`
 }
 
 // BeforeCreate hook to generate UUID before creating
@@ -91,6 +395,18 @@ func (o *Order) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// FormatPrices fills in the display prices of the order, its items and their loaded products
+func (o *Order) FormatPrices() {
+	o.TotalFormatted = NewMoney(int64(o.TotalCents), o.Currency).Format()
+	for i := range o.Items {
+		item := &o.Items[i]
+		item.PriceFormatted = NewMoney(int64(item.PriceCents), o.Currency).Format()
+		if item.Product != nil {
+			item.Product.FormatPrices()
+		}
+	}
+}
+
 // OrderItem represents a line item in an order
 type OrderItem struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
@@ -100,8 +416,14 @@ type OrderItem struct {
 	Product    *Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
 	PriceCents int       `gorm:"not null" json:"price_cents"`
 	Quantity   int       `gorm:"not null" json:"quantity"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	UnitType   string    `gorm:"not null;default:'each'" json:"unit_type"` // each, weight
+	// FlashSaleID is the flash sale the item was bought in, if any
+	FlashSaleID *uuid.UUID `gorm:"type:uuid;index" json:"flash_sale_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// PriceFormatted is the display unit price, only filled in on request
+	PriceFormatted string `gorm:"-" json:"price_formatted,omitempty"`
 }
 
 // BeforeCreate hook to generate UUID before creating
@@ -111,3 +433,249 @@ func (oi *OrderItem) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// LineTotalCents returns the price of quantity units of the item at its snapshotted price
+func (oi *OrderItem) LineTotalCents(quantity int) int64 {
+	return LineTotalCents(oi.PriceCents, quantity, oi.UnitType)
+}
+
+// Stock movement reasons
+const (
+	StockReasonOrder  = "order"
+	StockReasonCancel = "cancel"
+	StockReasonAdjust = "adjust"
+	StockReasonImport = "import"
+	StockReasonRefund = "refund"
+)
+
+// StockMovement records a single change to a product's stock level
+type StockMovement struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	ProductID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	Delta       int        `gorm:"not null" json:"delta"`
+	Reason      string     `gorm:"not null" json:"reason"` // order, cancel, adjust, import, refund
+	ReferenceID *uuid.UUID `gorm:"type:uuid" json:"reference_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (sm *StockMovement) BeforeCreate(tx *gorm.DB) error {
+	if sm.ID == uuid.Nil {
+		sm.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebhookDelivery records a single outbound webhook delivery attempt
+type WebhookDelivery struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	DeliveryID uuid.UUID `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	URL        string    `gorm:"not null" json:"url"`
+	Attempt    int       `gorm:"not null" json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `gorm:"not null" json:"success"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (wd *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if wd.ID == uuid.Nil {
+		wd.ID = uuid.New()
+	}
+	return nil
+}
+
+// Refund records money returned to a customer for some of an order's items
+type Refund struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key;" json:"id"`
+	OrderID     uuid.UUID    `gorm:"type:uuid;not null;index" json:"order_id"`
+	AmountCents int          `gorm:"not null" json:"amount_cents"`
+	Currency    string       `gorm:"not null" json:"currency"`
+	Reason      string       `json:"reason"`
+	CreatedBy   uuid.UUID    `gorm:"type:uuid;not null" json:"created_by"`
+	Items       []RefundItem `gorm:"foreignKey:RefundID" json:"items"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// RefundItem is the part of a refund covering one order line item.
+// AmountCents is computed from the price snapshotted on the order item.
+type RefundItem struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	RefundID    uuid.UUID `gorm:"type:uuid;not null;index" json:"refund_id"`
+	OrderItemID uuid.UUID `gorm:"type:uuid;not null;index" json:"order_item_id"`
+	Quantity    int       `gorm:"not null" json:"quantity"`
+	AmountCents int       `gorm:"not null" json:"amount_cents"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (ri *RefundItem) BeforeCreate(tx *gorm.DB) error {
+	if ri.ID == uuid.Nil {
+		ri.ID = uuid.New()
+	}
+	return nil
+}
+
+// OrderAdjustment records a manual change to an order's total, such as a goodwill credit.
+// AmountCents is signed: negative amounts lower the total.
+type OrderAdjustment struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	OrderID     uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	AmountCents int       `gorm:"not null" json:"amount_cents"`
+	Currency    string    `gorm:"not null" json:"currency"`
+	Reason      string    `gorm:"not null" json:"reason"`
+	CreatedBy   uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (oa *OrderAdjustment) BeforeCreate(tx *gorm.DB) error {
+	if oa.ID == uuid.Nil {
+		oa.ID = uuid.New()
+	}
+	return nil
+}
+
+// Order discount types
+const (
+	OrderDiscountPercent = "percent"
+	OrderDiscountFixed   = "fixed"
+)
+
+// OrderDiscount records a one-off discount granted by support on a pending order.
+// Value is the percent off or the fixed amount in cents; AmountCents is what was taken off the total.
+type OrderDiscount struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	OrderID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	Type        string    `gorm:"not null" json:"type"` // percent, fixed
+	Value       int       `gorm:"not null" json:"value"`
+	AmountCents int       `gorm:"not null" json:"amount_cents"`
+	Currency    string    `gorm:"not null" json:"currency"`
+	Reason      string    `gorm:"not null" json:"reason"`
+	CreatedBy   uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (od *OrderDiscount) BeforeCreate(tx *gorm.DB) error {
+	if od.ID == uuid.Nil {
+		od.ID = uuid.New()
+	}
+	return nil
+}
+
+// OutboxEvent is a webhook event written in the same transaction as the change it describes.
+// A background relay delivers unsent events, so an event is published if and only if its
+// transaction commits.
+type OutboxEvent struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	Type      string     `gorm:"not null" json:"type"`
+	Payload   string     `gorm:"type:jsonb;not null" json:"payload"`
+	RequestID string     `json:"request_id,omitempty"`
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	SentAt    *time.Time `gorm:"index" json:"sent_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (oe *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if oe.ID == uuid.Nil {
+		oe.ID = uuid.New()
+	}
+	return nil
+}
+
+// OrderNote is a support annotation on an order
+type OrderNote struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	OrderID    uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	AuthorID   uuid.UUID `gorm:"type:uuid;not null" json:"author_id"`
+	Author     *User     `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Body       string    `gorm:"not null" json:"body"`
+	IsInternal bool      `gorm:"not null;default:true" json:"is_internal"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (on *OrderNote) BeforeCreate(tx *gorm.DB) error {
+	if on.ID == uuid.Nil {
+		on.ID = uuid.New()
+	}
+	return nil
+}
+
+// Review represents a user's rating and review of a product
+type Review struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ProductID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_product_user" json:"product_id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_product_user" json:"user_id"`
+	User         *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Rating       int       `gorm:"not null" json:"rating"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body"`
+	HelpfulCount int       `gorm:"not null;default:0" json:"helpful_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (r *Review) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// ReviewVote records that a user marked a review as helpful
+type ReviewVote struct {
+	ReviewID  uuid.UUID `gorm:"type:uuid;primary_key" json:"review_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackInStockSubscription asks for a notification when an out-of-stock product is restocked.
+// A user has at most one pending subscription per product.
+type BackInStockSubscription struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_back_in_stock_pending,where:NOT notified" json:"product_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_back_in_stock_pending,where:NOT notified" json:"user_id"`
+	Notified  bool      `gorm:"not null;default:false" json:"notified"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (s *BackInStockSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// PasswordSetupToken lets an invited user choose their password.
+// Only the SHA-256 hash of the token is stored; a token can be used once, before it expires.
+type PasswordSetupToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID before creating
+func (t *PasswordSetupToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrCurrencyMismatch is returned when combining amounts in different currencies
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an amount in the smallest currency unit together with its currency
+type Money struct {
+	AmountCents int64  `json:"amount_cents"`
+	Currency    string `json:"currency"`
+}
+
+// NewMoney creates a Money value with a normalized currency code
+func NewMoney(amountCents int64, currency string) Money {
+	return Money{
+		AmountCents: amountCents,
+		Currency:    NormalizeCurrency(currency),
+	}
+}
+
+// NormalizeCurrency returns a currency code in its canonical uppercase form
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// IsZero returns true if the amount is zero
+func (m Money) IsZero() bool {
+	return m.AmountCents == 0
+}
+
+// Add returns the sum of two amounts.
+// A zero value without a currency adopts the other amount's currency, so totals can start from Money{}.
+func (m Money) Add(other Money) (Money, error) {
+	switch {
+	case m.Currency == "" && m.IsZero():
+		return other, nil
+	case other.Currency == "" && other.IsZero():
+		return m, nil
+	case m.Currency != other.Currency:
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	return NewMoney(m.AmountCents+other.AmountCents, m.Currency), nil
+}
+
+// Mul returns the amount multiplied by a quantity
+func (m Money) Mul(qty int) Money {
+	return NewMoney(m.AmountCents*int64(qty), m.Currency)
+}
+
+// currencyFormat describes how amounts in a currency are displayed
+type currencyFormat struct {
+	symbol   string
+	decimals int
+}
+
+// currencyFormats holds the display formats of common currencies.
+// Currencies not listed are shown with their code and two decimals.
+var currencyFormats = map[string]currencyFormat{
+	"USD": {symbol: "$", decimals: 2},
+	"EUR": {symbol: "€", decimals: 2},
+	"GBP": {symbol: "£", decimals: 2},
+	"CAD": {symbol: "CA$", decimals: 2},
+	"AUD": {symbol: "A$", decimals: 2},
+	"INR": {symbol: "₹", decimals: 2},
+	"CNY": {symbol: "CN¥", decimals: 2},
+	"JPY": {symbol: "¥", decimals: 0},
+	"KRW": {symbol: "₩", decimals: 0},
+}
+
+// Format renders the amount for display, e.g. "$1,234.56" or "¥500".
+// Amounts are in the currency's smallest unit, so zero-decimal currencies like JPY are shown as is.
+func (m Money) Format() string {
+	format, ok := currencyFormats[m.Currency]
+	if !ok {
+		format = currencyFormat{symbol: m.Currency + " ", decimals: 2}
+	}
+
+	amount := m.AmountCents
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	scale := int64(1)
+	for i := 0; i < format.decimals; i++ {
+		scale *= 10
+	}
+
+	out := sign + format.symbol + groupThousands(strconv.FormatInt(amount/scale, 10))
+	if format.decimals > 0 {
+		out += fmt.Sprintf(".%0*d", format.decimals, amount%scale)
+	}
+	return out
+}
+
+// groupThousands inserts commas between groups of three digits
+func groupThousands(digits string) string {
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
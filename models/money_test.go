@@ -0,0 +1,306 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMoneyAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		want    Money
+		wantErr error
+	}{
+		{"same currency", NewMoney(150, "USD"), NewMoney(275, "USD"), NewMoney(425, "USD"), nil},
+		{"negative amount", NewMoney(1000, "EUR"), NewMoney(-250, "EUR"), NewMoney(750, "EUR"), nil},
+		{"normalized codes match", NewMoney(100, " usd "), NewMoney(1, "USD"), NewMoney(101, "USD"), nil},
+		{"zero value adopts other currency", Money{}, NewMoney(500, "GBP"), NewMoney(500, "GBP"), nil},
+		{"other zero value keeps currency", NewMoney(500, "GBP"), Money{}, NewMoney(500, "GBP"), nil},
+		{"zero amount with currency still checked", NewMoney(0, "USD"), NewMoney(100, "EUR"), Money{}, ErrCurrencyMismatch},
+		{"currency mismatch", NewMoney(100, "USD"), NewMoney(100, "EUR"), Money{}, ErrCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Add() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"USD", "USD"},
+		{"usd", "USD"},
+		{" eUr\t", "EUR"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeCurrency(tt.in); got != tt.want {
+			t.Errorf("NormalizeCurrency(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Money
+		qty  int
+		want Money
+	}{
+		{"single", NewMoney(1999, "USD"), 1, NewMoney(1999, "USD")},
+		{"several", NewMoney(1999, "USD"), 3, NewMoney(5997, "USD")},
+		{"zero quantity", NewMoney(1999, "USD"), 0, NewMoney(0, "USD")},
+		{"beyond int32", NewMoney(1_000_000_00, "USD"), 100, NewMoney(10_000_000_000, "USD")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.Mul(tt.qty); got != tt.want {
+				t.Errorf("Mul(%d) = %+v, want %+v", tt.qty, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineTotalCents(t *testing.T) {
+	tests := []struct {
+		name       string
+		priceCents int
+		quantity   int
+		unitType   string
+		want       int64
+	}{
+		{"each", 250, 4, UnitTypeEach, 1000},
+		{"whole kilogram", 1200, 1000, UnitTypeWeight, 1200},
+		{"part kilogram", 1200, 250, UnitTypeWeight, 300},
+		{"rounds half up", 999, 500, UnitTypeWeight, 500},
+		{"rounds down below half", 499, 1, UnitTypeWeight, 0},
+		{"rounds up above half", 999, 1, UnitTypeWeight, 1},
+		{"large weight", 100_000, 1_000_000, UnitTypeWeight, 100_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LineTotalCents(tt.priceCents, tt.quantity, tt.unitType); got != tt.want {
+				t.Errorf("LineTotalCents(%d, %d, %q) = %d, want %d", tt.priceCents, tt.quantity, tt.unitType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductLineTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		product  Product
+		quantity int
+		want     Money
+	}{
+		{"each", Product{PriceCents: 500, Currency: "usd", UnitType: UnitTypeEach}, 3, NewMoney(1500, "USD")},
+		{"by weight", Product{PriceCents: 1500, Currency: "EUR", UnitType: UnitTypeWeight}, 333, NewMoney(500, "EUR")},
+		{
+			"tier applies",
+			Product{PriceCents: 1000, Currency: "USD", UnitType: UnitTypeEach, PriceTiers: []PriceTier{{MinQuantity: 10, PriceCents: intPtr(800)}}},
+			10,
+			NewMoney(8000, "USD"),
+		},
+		{
+			"tier below minimum",
+			Product{PriceCents: 1000, Currency: "USD", UnitType: UnitTypeEach, PriceTiers: []PriceTier{{MinQuantity: 10, PriceCents: intPtr(800)}}},
+			9,
+			NewMoney(9000, "USD"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.product.LineTotal(tt.quantity); got != tt.want {
+				t.Errorf("LineTotal(%d) = %+v, want %+v", tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductUnitPriceCents(t *testing.T) {
+	product := Product{
+		PriceCents: 1000,
+		PriceTiers: []PriceTier{
+			{MinQuantity: 5, PercentOff: intPtr(5)},
+			{MinQuantity: 10, PriceCents: intPtr(800)},
+			{MinQuantity: 20, PercentOff: intPtr(25)},
+			// A tier that is worse than a lower one never raises the price
+			{MinQuantity: 50, PriceCents: intPtr(900)},
+		},
+	}
+	tests := []struct {
+		quantity int
+		want     int
+	}{
+		{1, 1000},
+		{4, 1000},
+		{5, 950},
+		{10, 800},
+		{19, 800},
+		{20, 750},
+		{50, 750},
+	}
+	for _, tt := range tests {
+		if got := product.UnitPriceCents(tt.quantity); got != tt.want {
+			t.Errorf("UnitPriceCents(%d) = %d, want %d", tt.quantity, got, tt.want)
+		}
+	}
+
+	// Percentages round half up to the nearest cent
+	odd := Product{PriceCents: 999, PriceTiers: []PriceTier{{MinQuantity: 2, PercentOff: intPtr(15)}}}
+	if got := odd.UnitPriceCents(2); got != 849 {
+		t.Errorf("UnitPriceCents(2) = %d, want 849", got)
+	}
+}
+
+func TestFlashSaleSalePriceCents(t *testing.T) {
+	tests := []struct {
+		percentOff int
+		base       int
+		want       int
+	}{
+		{20, 1000, 800},
+		{15, 999, 849},
+		{50, 1, 1},
+		{99, 1000, 10},
+		{1, 0, 0},
+	}
+	for _, tt := range tests {
+		sale := FlashSale{PercentOff: tt.percentOff}
+		if got := sale.SalePriceCents(tt.base); got != tt.want {
+			t.Errorf("%d%% off SalePriceCents(%d) = %d, want %d", tt.percentOff, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestProductFlashSalePricing(t *testing.T) {
+	product := Product{
+		PriceCents: 1000,
+		PriceTiers: []PriceTier{{MinQuantity: 10, PriceCents: intPtr(700)}},
+		FlashSale:  &ProductFlashSale{PercentOff: 20, SalePriceCents: 800},
+	}
+	tests := []struct {
+		quantity    int
+		want        int
+		onFlashSale bool
+	}{
+		{1, 800, true},
+		{9, 800, true},
+		// A better tier price wins over the sale, and the units don't count toward its limit
+		{10, 700, false},
+	}
+	for _, tt := range tests {
+		if got := product.UnitPriceCents(tt.quantity); got != tt.want {
+			t.Errorf("UnitPriceCents(%d) = %d, want %d", tt.quantity, got, tt.want)
+		}
+		if got := product.OnFlashSale(tt.quantity); got != tt.onFlashSale {
+			t.Errorf("OnFlashSale(%d) = %v, want %v", tt.quantity, got, tt.onFlashSale)
+		}
+	}
+
+	// Once the sale ends the product is loaded without it and goes back to its own prices
+	product.FlashSale = nil
+	if got := product.UnitPriceCents(1); got != 1000 {
+		t.Errorf("after the sale UnitPriceCents(1) = %d, want 1000", got)
+	}
+	if got := product.UnitPriceCents(10); got != 700 {
+		t.Errorf("after the sale UnitPriceCents(10) = %d, want 700", got)
+	}
+	if product.OnFlashSale(1) {
+		t.Error("after the sale OnFlashSale(1) = true, want false")
+	}
+}
+
+func TestProductFlashSaleAllowsPurchase(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    *int
+		bought   int
+		quantity int
+		want     bool
+	}{
+		{"no limit", nil, 100, 50, true},
+		{"under the limit", intPtr(3), 0, 2, true},
+		{"up to the limit", intPtr(3), 1, 2, true},
+		{"past the limit", intPtr(3), 0, 4, false},
+		{"past the limit with earlier orders", intPtr(3), 2, 2, false},
+		{"limit already used", intPtr(3), 3, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sale := ProductFlashSale{PerUserLimit: tt.limit}
+			if got := sale.AllowsPurchase(tt.bought, tt.quantity); got != tt.want {
+				t.Errorf("AllowsPurchase(%d, %d) = %v, want %v", tt.bought, tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{NewMoney(0, "USD"), "$0.00"},
+		{NewMoney(5, "USD"), "$0.05"},
+		{NewMoney(123456, "USD"), "$1,234.56"},
+		{NewMoney(-1999, "EUR"), "-€19.99"},
+		{NewMoney(100000000, "GBP"), "£1,000,000.00"},
+		{NewMoney(500, "JPY"), "¥500"},
+		{NewMoney(1234567, "KRW"), "₩1,234,567"},
+		{NewMoney(1050, "CHF"), "CHF 10.50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.m.Format(); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{NewMoney(1999, "USD"), `{"amount_cents":1999,"currency":"USD"}`},
+		{NewMoney(1999, "usd"), `{"amount_cents":1999,"currency":"USD"}`},
+		{NewMoney(-50, "EUR"), `{"amount_cents":-50,"currency":"EUR"}`},
+		{Money{}, `{"amount_cents":0,"currency":""}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			data, err := json.Marshal(tt.m)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", data, tt.want)
+			}
+
+			var decoded Money
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if decoded != tt.m {
+				t.Errorf("Unmarshal() = %+v, want %+v", decoded, tt.m)
+			}
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notFound answers requests that match no route with the API's JSON error format
+func notFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"error": "not found",
+	})
+}
+
+// methodNotAllowed answers requests whose path matches a route registered for other methods.
+// The Allow header lists those methods; the route table is read on first use, once all routes are registered.
+func methodNotAllowed(router *gin.Engine) gin.HandlerFunc {
+	var (
+		once   sync.Once
+		routes gin.RoutesInfo
+	)
+
+	return func(c *gin.Context) {
+		once.Do(func() {
+			routes = router.Routes()
+		})
+
+		seen := make(map[string]bool)
+		var allowed []string
+		for _, route := range routes {
+			if !seen[route.Method] && routeMatches(route.Path, c.Request.URL.Path) {
+				seen[route.Method] = true
+				allowed = append(allowed, route.Method)
+			}
+		}
+		sort.Strings(allowed)
+
+		c.Header("Allow", strings.Join(allowed, ", "))
+		c.JSON(http.StatusMethodNotAllowed, gin.H{
+			"error": "method not allowed",
+		})
+	}
+}
+
+// routeMatches reports whether a request path matches a route pattern.
+// :name matches one path segment and *name matches the rest of the path.
+func routeMatches(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "*") {
+			return true
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if strings.HasPrefix(part, ":") {
+			if pathParts[i] == "" {
+				return false
+			}
+			continue
+		}
+		if part != pathParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
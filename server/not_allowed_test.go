@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestUnmatchedRoutes(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		wantStatus   int
+		wantAllow    string
+		wantLocation string
+		wantError    string
+	}{
+		{"unknown path", http.MethodGet, "/api/v1/nope", http.StatusNotFound, "", "", "not found"},
+		{"wrong method", http.MethodPatch, "/version", http.StatusMethodNotAllowed, "GET", "", "method not allowed"},
+		{"wrong method on a param route", http.MethodPatch, "/api/v1/me/addresses/6f1c2a9e-4b57-4c1f-9d3e-2a8f1b7c5d40", http.StatusMethodNotAllowed, "DELETE, PUT", "", "method not allowed"},
+		{"trailing slash", http.MethodGet, "/version/", http.StatusMovedPermanently, "", "/version", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(s, tt.method, tt.path, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+			if tt.wantError == "" {
+				return
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error != tt.wantError {
+				t.Errorf("body = %s, want the JSON error %q", w.Body.String(), tt.wantError)
+			}
+		})
+	}
+}
+
+func TestRouteMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/version", "/version", true},
+		{"/version", "/version/", true},
+		{"/version", "/versions", false},
+		{"/api/v1/products/:id", "/api/v1/products/42", true},
+		{"/api/v1/products/:id", "/api/v1/products", false},
+		{"/api/v1/products/:id", "/api/v1/products/42/reviews", false},
+		{"/api/v1/products/:id/reviews", "/api/v1/products/42/reviews", true},
+		{"/static/*filepath", "/static/css/site.css", true},
+		{"/", "/", true},
+	}
+	for _, tt := range tests {
+		if got := routeMatches(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("routeMatches(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -9,56 +14,173 @@ import (
 	"github.com/sainudheenp/goecom/config"
 	store "github.com/sainudheenp/goecom/db"
 	handler "github.com/sainudheenp/goecom/handlers"
+	"github.com/sainudheenp/goecom/internal/jobs"
+	"github.com/sainudheenp/goecom/internal/jwtkeys"
+	"github.com/sainudheenp/goecom/internal/version"
+	"github.com/sainudheenp/goecom/internal/webhook"
 	"github.com/sainudheenp/goecom/middleware"
+	"github.com/sainudheenp/goecom/tracing"
 	"gorm.io/gorm/logger"
 )
 
+// startupPingTimeout bounds the database check made once migrations have run
+const startupPingTimeout = 5 * time.Second
+
 // Server represents the HTTP server
 type Server struct {
-	router *gin.Engine
-	config *config.Config
-	db     *store.DB
+	router          *gin.Engine
+	httpServer      *http.Server
+	config          *config.Config
+	buildInfo       version.Info
+	db              *store.DB
+	health          *handler.HealthHandler
+	jwtKeys         *jwtkeys.Keys
+	events          *webhook.Publisher
+	maintenance     *middleware.Maintenance
+	roles           *middleware.Roles
+	rateLimiter     *middleware.RateLimiter
+	authRateLimiter *middleware.RateLimiter
+	cartCleanup     *jobs.CartCleanup
+	outboxRelay     *jobs.OutboxRelay
+	tracingShutdown func(context.Context) error
 }
 
 // NewServer creates a new server instance
-func NewServer(cfg *config.Config) (*Server, error) {
+func NewServer(cfg *config.Config, buildInfo version.Info) (*Server, error) {
 	// Set Gin mode
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Initialize tracing before the database so query spans use the configured provider
+	tracingShutdown, err := tracing.Setup(context.Background(), cfg.Tracing.OTLPEndpoint, cfg.Tracing.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load token signing keys
+	jwtKeys := jwtkeys.NewHMAC(cfg.JWT.Secret)
+	if cfg.JWT.Algorithm == jwtkeys.AlgorithmRS256 {
+		if jwtKeys, err = jwtkeys.NewRSA(cfg.JWT.PrivateKeyFile); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize database
 	logLevel := logger.Info
 	if cfg.IsDevelopment() {
 		logLevel = logger.Info
 	}
 
-	database, err := store.NewDB(cfg.Database.URL, logLevel)
+	database, err := store.NewDB(cfg.Database.URL, cfg.Database.ReadURL, logLevel, time.Duration(cfg.Log.SlowQueryMS)*time.Millisecond, cfg.Database.TxMaxAttempts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Run migrations
-	log.Println("Running database migrations...")
-	if err := database.AutoMigrate(); err != nil {
+	// Create router
+	router, err := newRouter(cfg.Server.TrustedProxies)
+	if err != nil {
 		return nil, err
 	}
+	roles, err := middleware.NewRoles(cfg.Security.RolePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ROLE_PERMISSIONS: %w", err)
+	}
+	handler.RegisterValidation(cfg.Catalog.Currencies, handler.PriceLimits{
+		AllowFree: cfg.Catalog.AllowFreeProducts,
+		MaxCents:  cfg.Catalog.MaxPriceCents,
+	})
 
-	// Create router
-	router := gin.New()
+	// Initialize webhook publisher; events are dropped when no URLs are configured
+	endpoints := make([]webhook.Endpoint, 0, len(cfg.Webhook.URLs))
+	for _, url := range cfg.Webhook.URLs {
+		endpoints = append(endpoints, webhook.Endpoint{URL: url, Secret: cfg.Webhook.Secret})
+	}
+	webhookClient := webhook.NewClient(database.DB, time.Duration(cfg.Webhook.TimeoutSeconds)*time.Second, cfg.Webhook.MaxAttempts, time.Second)
 
 	s := &Server{
 		router: router,
-		config: cfg,
-		db:     database,
+		httpServer: &http.Server{
+			Addr:    ":" + cfg.Server.Port,
+			Handler: router,
+		},
+		config:          cfg,
+		buildInfo:       buildInfo,
+		db:              database,
+		health:          handler.NewHealthHandler(database.DB, time.Duration(cfg.Health.CacheSeconds)*time.Second),
+		jwtKeys:         jwtKeys,
+		events:          webhook.NewPublisher(webhookClient, endpoints),
+		roles:           roles,
+		maintenance:     middleware.NewMaintenance(cfg.Maintenance.Enabled, cfg.Maintenance.RetryAfterSeconds, "/api/v1/admin/maintenance"),
+		rateLimiter:     middleware.NewMethodRateLimiter(cfg.RateLimit.ReadRequests, cfg.RateLimit.WriteRequests, cfg.RateLimit.WindowMinutes, cfg.RateLimit.ExemptPaths...),
+		authRateLimiter: middleware.NewRateLimiter(cfg.RateLimit.AuthRequests, cfg.RateLimit.AuthWindowMinutes),
+		tracingShutdown: tracingShutdown,
 	}
 
 	s.setupMiddleware()
 	s.setupRoutes()
 
+	log.Println("Server initialized")
 	return s, nil
 }
 
+// start does the slow startup work: it runs migrations, checks the database answers and starts the
+// background jobs. Until it has finished, readiness reports starting and other routes answer 503.
+func (s *Server) start() error {
+	log.Println("Running database migrations...")
+	if err := s.db.AutoMigrate(); err != nil {
+		return err
+	}
+
+	// Make sure the database answers after migrating, before traffic is let in
+	pingCtx, cancel := context.WithTimeout(context.Background(), startupPingTimeout)
+	err := s.db.Ping(pingCtx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("database not reachable after migrations: %w", err)
+	}
+
+	// Start background jobs; a zero TTL keeps carts forever
+	if s.config.Cart.TTLHours > 0 {
+		s.cartCleanup = jobs.NewCartCleanup(
+			s.db.DB,
+			time.Duration(s.config.Cart.TTLHours)*time.Hour,
+			time.Duration(s.config.Cart.CleanupIntervalMinutes)*time.Minute,
+		)
+		s.cartCleanup.Start()
+	}
+	if s.events.Enabled() {
+		s.outboxRelay = jobs.NewOutboxRelay(
+			s.db.DB,
+			s.events,
+			time.Duration(s.config.Webhook.OutboxPollSeconds)*time.Second,
+		)
+		s.outboxRelay.Start()
+	}
+
+	s.health.MarkReady()
+	log.Println("Server ready")
+	return nil
+}
+
+// newRouter creates the Gin engine.
+// Only X-Forwarded-For from trustedProxies is honored; with none configured ClientIP is the peer address.
+func newRouter(trustedProxies []string) (*gin.Engine, error) {
+	// A path with a stray trailing slash redirects to the registered route, and a
+	// known path requested with the wrong method gets a 405 instead of a 404
+	router := gin.New()
+	router.RedirectTrailingSlash = true
+	router.RedirectFixedPath = false
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(notFound)
+	router.NoMethod(methodNotAllowed(router))
+
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	return router, nil
+}
+
 // setupMiddleware configures middleware
 func (s *Server) setupMiddleware() {
 	// Recovery middleware
@@ -67,75 +189,252 @@ func (s *Server) setupMiddleware() {
 	// Request ID middleware
 	s.router.Use(middleware.RequestID())
 
+	// HTTPS redirect and security headers
+	if s.config.Security.ForceHTTPS {
+		s.router.Use(middleware.HTTPSRedirect("/health*"))
+	}
+	if s.config.Security.SecureHeaders {
+		s.router.Use(middleware.SecureHeaders(s.config.Security.HSTSMaxAgeSeconds, s.config.Security.ContentSecurityPolicy))
+	}
+
+	// Tracing middleware
+	s.router.Use(middleware.Tracing())
+
 	// Logger middleware
-	s.router.Use(middleware.Logger())
+	s.router.Use(middleware.Logger(time.Duration(s.config.Log.SlowRequestMS) * time.Millisecond))
+	if s.config.Log.RequestBodies {
+		s.router.Use(middleware.RequestBodyLogger())
+	}
 
 	// CORS middleware
 	corsConfig := cors.Config{
 		AllowOrigins:     s.config.CORS.Origins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Request-ID"},
-		ExposeHeaders:    []string{"X-Request-ID"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Request-ID", handler.ClientSourceHeader},
+		ExposeHeaders:    []string{"X-Request-ID", handler.TotalCountHeader, "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
 	s.router.Use(cors.New(corsConfig))
 
+	// Only health checks are answered until startup has finished
+	s.router.Use(middleware.RequireStarted(s.health.IsReady, "/health*"))
+
 	// Rate limiting middleware
-	rateLimiter := middleware.NewRateLimiter(
-		s.config.RateLimit.Requests,
-		s.config.RateLimit.WindowMinutes,
-	)
-	s.router.Use(rateLimiter.Middleware())
+	s.router.Use(s.rateLimiter.Middleware())
+
+	// Maintenance mode middleware
+	s.router.Use(s.maintenance.Middleware())
 }
 
 // setupRoutes configures routes
 func (s *Server) setupRoutes() {
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(s.db.DB, s.config.JWT.Secret, s.config.JWT.ExpiresHours, s.config.Security.BcryptCost)
-	productHandler := handler.NewProductHandler(s.db.DB)
-
-	// Health check
-	s.router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-			"time":   time.Now().UTC(),
-		})
-	})
+	authHandler := handler.NewAuthHandler(s.db, s.jwtKeys, s.config.JWT.ExpiresHours, s.config.JWT.AdminExpiresHours, s.config.Security.BcryptCost)
+	productHandler := handler.NewProductHandler(s.db, s.config.Catalog.LowStockThreshold, s.config.Catalog.DefaultCurrency, s.config.Catalog.HideOutOfStock, s.config.Catalog.MaxProductImages, s.config.Catalog.SearchMinLength, s.config.Pagination.ProductsDefaultSize, s.config.Catalog.DefaultSort, s.events)
+	cartLimits := handler.CartLimits{
+		MaxItemQuantity: s.config.Cart.MaxItemQuantity,
+		MaxItems:        s.config.Cart.MaxItems,
+	}
+	cartHandler := handler.NewCartHandler(s.db, cartLimits, time.Duration(s.config.Cart.AddDedupWindowMS)*time.Millisecond)
+	orderHandler := handler.NewOrderHandler(s.db, cartLimits, time.Duration(s.config.Cart.HoldMinutes)*time.Minute, s.config.Pagination.OrdersDefaultSize, s.events)
+	statsHandler := handler.NewStatsHandler(s.db.DB)
+	addressHandler := handler.NewAddressHandler(s.db)
+	orderNoteHandler := handler.NewOrderNoteHandler(s.db.DB)
+	refundHandler := handler.NewRefundHandler(s.db, s.events)
+	orderAdjustmentHandler := handler.NewOrderAdjustmentHandler(s.db)
+	flashSaleHandler := handler.NewFlashSaleHandler(s.db)
+	reviewHandler := handler.NewReviewHandler(s.db)
+	maintenanceHandler := handler.NewMaintenanceHandler(s.maintenance)
+	userHandler := handler.NewUserHandler(s.db, s.roles, s.config.Security.BcryptCost, time.Duration(s.config.Security.InviteTTLHours)*time.Hour, s.events)
+	rateLimitHandler := handler.NewRateLimitHandler(s.rateLimiter)
+	versionHandler := handler.NewVersionHandler(s.buildInfo)
+
+	// Health checks
+	s.router.GET("/health", s.health.Live)
+	s.router.GET("/health/ready", s.health.Ready)
+	s.router.GET("/version", versionHandler.GetVersion)
+
+	// Public keys for verifying access tokens
+	s.router.GET("/.well-known/jwks.json", authHandler.JWKS)
 
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
 	{
-		// Public routes
-		auth := v1.Group("/auth")
+		// Public routes; auth gets its own, stricter limit on top of the global one
+		auth := v1.Group("/auth", s.authRateLimiter.Middleware(), middleware.NoStore())
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/set-password", authHandler.SetPassword)
 		}
 
-		// Public product routes
-		v1.GET("/products", productHandler.ListProducts)
-		v1.GET("/products/:id", productHandler.GetProduct)
+		// Rate limit status
+		v1.GET("/rate-limit", rateLimitHandler.GetRateLimit)
+
+		// Public product routes; list and detail responses may be cached by CDNs
+		productCache := middleware.PublicCache(s.config.Catalog.CacheMaxAge)
+		v1.GET("/products", productCache, productHandler.ListProducts)
+		v1.GET("/products/skus", productHandler.GetProductsBySKUs)
+		v1.GET("/products/:id", productCache, productHandler.GetProduct)
+		v1.GET("/products/:id/reviews", reviewHandler.ListReviews)
 
 		// Protected routes
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware(s.db.DB, s.config.JWT.Secret))
+		protected.Use(middleware.NoStore(), middleware.AuthMiddleware(s.db.DB, s.jwtKeys))
 		{
 			// User routes
 			protected.GET("/me", authHandler.GetMe)
+			protected.GET("/me/orders/summary", orderHandler.GetOrderSummary)
+
+			// Saved address routes
+			protected.GET("/me/addresses", addressHandler.ListAddresses)
+			protected.POST("/me/addresses", addressHandler.CreateAddress)
+			protected.PUT("/me/addresses/:id", addressHandler.UpdateAddress)
+			protected.DELETE("/me/addresses/:id", addressHandler.DeleteAddress)
+
+			// Cart routes
+			protected.GET("/cart", cartHandler.GetCart)
+			protected.POST("/cart", cartHandler.AddToCart)
+			protected.POST("/cart/bulk", cartHandler.BulkAddToCart)
+			protected.POST("/cart/validate", cartHandler.ValidateCart)
+			protected.DELETE("/cart/:item_id", cartHandler.RemoveFromCart)
+
+			// Review routes
+			protected.POST("/products/:id/reviews", reviewHandler.CreateReview)
+			protected.POST("/reviews/:id/helpful", reviewHandler.MarkHelpful)
+
+			// Back-in-stock notification routes
+			protected.POST("/products/:id/notify-me", productHandler.NotifyMe)
+
+			// Order routes
+			protected.POST("/orders", orderHandler.CreateOrder)
+			protected.POST("/orders/preview", orderHandler.PreviewOrder)
+			protected.GET("/orders", orderHandler.ListOrders)
+			protected.GET("/orders/statuses", orderHandler.ListOrderStatuses)
+			protected.GET("/orders/:id", orderHandler.GetOrder)
+			protected.GET("/orders/:id/receipt", orderHandler.GetOrderReceipt)
+			protected.GET("/orders/number/:number", orderHandler.GetOrderByNumber)
+			protected.POST("/orders/:id/reorder", orderHandler.Reorder)
 		}
+
+		// Admin routes; each area needs its own permission, see ROLE_PERMISSIONS
+		admin := protected.Group("/admin")
+		catalogRead := admin.Group("", s.roles.RequirePermission("catalog:read"))
+		{
+			catalogRead.GET("/products", productHandler.ListAdminProducts)
+			catalogRead.GET("/products/export", productHandler.ExportProducts)
+			catalogRead.GET("/products/:id", productHandler.GetAdminProduct)
+			catalogRead.GET("/products/:id/stock-history", productHandler.GetStockHistory)
+			catalogRead.GET("/flash-sales", flashSaleHandler.ListFlashSales)
+		}
+		catalogWrite := admin.Group("", s.roles.RequirePermission("catalog:write"))
+		{
+			catalogWrite.POST("/products", productHandler.CreateProduct)
+			catalogWrite.PUT("/products/:id", productHandler.UpdateProduct)
+			catalogWrite.POST("/products/:id/publish", productHandler.PublishProduct)
+			catalogWrite.POST("/products/:id/archive", productHandler.ArchiveProduct)
+			catalogWrite.POST("/products/:id/clone", productHandler.CloneProduct)
+			catalogWrite.PUT("/products/:id/featured", productHandler.SetFeaturedRank)
+			catalogWrite.PUT("/products/:id/price-tiers", productHandler.SetPriceTiers)
+			catalogWrite.POST("/products/:id/images/add", productHandler.AddProductImage)
+			catalogWrite.DELETE("/products/:id/images", productHandler.RemoveProductImage)
+			catalogWrite.POST("/products/stock-adjustments", productHandler.AdjustStock)
+			catalogWrite.POST("/flash-sales", flashSaleHandler.CreateFlashSale)
+			catalogWrite.DELETE("/flash-sales/:id", flashSaleHandler.DeleteFlashSale)
+		}
+		ordersRead := admin.Group("", s.roles.RequirePermission("orders:read"))
+		{
+			ordersRead.GET("/products/:id/orders", orderHandler.ListProductOrders)
+			ordersRead.GET("/orders", orderHandler.ListAllOrders)
+			ordersRead.GET("/orders/:id", orderHandler.GetAnyOrder)
+			ordersRead.GET("/orders/number/:number", orderHandler.GetAnyOrderByNumber)
+			ordersRead.GET("/orders/:id/notes", orderNoteHandler.ListOrderNotes)
+			ordersRead.GET("/orders/:id/refunds", refundHandler.ListRefunds)
+			ordersRead.GET("/orders/:id/adjustments", orderAdjustmentHandler.ListOrderAdjustments)
+			ordersRead.GET("/users/:id/orders", orderHandler.ListUserOrders)
+		}
+		ordersWrite := admin.Group("", s.roles.RequirePermission("orders:write"))
+		{
+			ordersWrite.POST("/orders/bulk-status", orderHandler.BulkUpdateOrderStatus)
+			ordersWrite.PATCH("/orders/:id", orderHandler.UpdateOrderStatus)
+			ordersWrite.POST("/orders/:id/notes", orderNoteHandler.CreateOrderNote)
+			ordersWrite.POST("/orders/:id/refunds", refundHandler.CreateRefund)
+			ordersWrite.POST("/orders/:id/adjust", orderAdjustmentHandler.CreateOrderAdjustment)
+			ordersWrite.POST("/orders/:id/discount", orderAdjustmentHandler.ApplyOrderDiscount)
+		}
+		usersRead := admin.Group("", s.roles.RequirePermission("users:read"))
+		{
+			usersRead.GET("/users", userHandler.ListUsers)
+			usersRead.GET("/users/:id/cart", cartHandler.GetUserCart)
+		}
+		usersWrite := admin.Group("", s.roles.RequirePermission("users:write"))
+		{
+			usersWrite.POST("/users/import", userHandler.ImportUsers)
+			usersWrite.DELETE("/users/:id", userHandler.DeleteUser)
+			usersWrite.POST("/users/:id/restore", userHandler.RestoreUser)
+		}
+		admin.GET("/stats/revenue", s.roles.RequirePermission("stats:read"), statsHandler.GetRevenue)
+		admin.POST("/maintenance", s.roles.RequirePermission("maintenance:write"), maintenanceHandler.SetMaintenance)
 	}
 }
 
-// Run starts the HTTP server
+// Run starts the HTTP server, finishes startup and blocks until the server is shut down.
+// HTTPS (with HTTP/2) is served when a TLS certificate and key are configured.
 func (s *Server) Run() error {
-	addr := ":" + s.config.Server.Port
-	log.Printf("Starting server on %s", addr)
-	return s.router.Run(addr)
+	// Listen first so probes get answers, and readiness its 503, while startup is still running
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		if s.config.TLSEnabled() {
+			log.Printf("Starting HTTPS server on %s", s.httpServer.Addr)
+			served <- s.httpServer.ServeTLS(listener, s.config.Server.TLSCertFile, s.config.Server.TLSKeyFile)
+		} else {
+			log.Printf("Starting server on %s", s.httpServer.Addr)
+			served <- s.httpServer.Serve(listener)
+		}
+	}()
+
+	if err := s.start(); err != nil {
+		s.httpServer.Close()
+		<-served
+		return fmt.Errorf("startup failed: %w", err)
+	}
+
+	err = <-served
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to finish
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
 }
 
 // Close closes the server and its resources
 func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if s.cartCleanup != nil {
+		if err := s.cartCleanup.Stop(ctx); err != nil {
+			log.Printf("Failed to stop cart cleanup: %v", err)
+		}
+	}
+	if s.outboxRelay != nil {
+		if err := s.outboxRelay.Stop(ctx); err != nil {
+			log.Printf("Failed to stop outbox relay: %v", err)
+		}
+	}
+	if err := s.tracingShutdown(ctx); err != nil {
+		log.Printf("Failed to shut down tracing: %v", err)
+	}
+
 	return s.db.Close()
 }
 
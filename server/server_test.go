@@ -0,0 +1,213 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainudheenp/goecom/config"
+	store "github.com/sainudheenp/goecom/db"
+	handler "github.com/sainudheenp/goecom/handlers"
+	"github.com/sainudheenp/goecom/internal/jwtkeys"
+	"github.com/sainudheenp/goecom/internal/webhook"
+	"github.com/sainudheenp/goecom/middleware"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestServer wires the full middleware stack and routes from the environment
+// onto a store that never connects, so requests rejected before any query can be exercised
+func newTestServer(t *testing.T, env map[string]string) *Server {
+	t.Helper()
+	s := newStartingTestServer(t, env)
+	s.health.MarkReady()
+	return s
+}
+
+// newStartingTestServer is newTestServer before startup has finished
+func newStartingTestServer(t *testing.T, env map[string]string) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DATABASE_URL", "postgres://localhost/test")
+	t.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+
+	gormDB, err := gorm.Open(postgres.Open("host=localhost dbname=test"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, err := newRouter(cfg.Server.TrustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles, err := middleware.NewRoles(cfg.Security.RolePermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{
+		router:          router,
+		config:          cfg,
+		db:              &store.DB{DB: gormDB},
+		health:          handler.NewHealthHandler(gormDB, time.Minute),
+		roles:           roles,
+		jwtKeys:         jwtkeys.NewHMAC(cfg.JWT.Secret),
+		events:          webhook.NewPublisher(nil, nil),
+		maintenance:     middleware.NewMaintenance(false, 0),
+		rateLimiter:     middleware.NewMethodRateLimiter(cfg.RateLimit.ReadRequests, cfg.RateLimit.WriteRequests, cfg.RateLimit.WindowMinutes, cfg.RateLimit.ExemptPaths...),
+		authRateLimiter: middleware.NewRateLimiter(cfg.RateLimit.AuthRequests, cfg.RateLimit.AuthWindowMinutes),
+	}
+	s.setupMiddleware()
+	s.setupRoutes()
+	return s
+}
+
+// serve sends a request with an optional JSON body through the server's router
+func serve(s *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestNewRouterClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+	}{
+		{"no proxies trusted", nil, "203.0.113.7:4000", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", []string{"10.0.0.0/8"}, "10.1.2.3:4000", "198.51.100.1", "198.51.100.1"},
+		{"trusted proxy chain", []string{"10.0.0.0/8"}, "10.1.2.3:4000", "198.51.100.1, 10.9.9.9", "198.51.100.1"},
+		{"untrusted peer", []string{"10.0.0.0/8"}, "203.0.113.7:4000", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy without header", []string{"10.1.2.3"}, "10.1.2.3:4000", "", "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := newRouter(tt.trustedProxies)
+			if err != nil {
+				t.Fatalf("newRouter() error = %v", err)
+			}
+			var got string
+			router.GET("/ip", func(c *gin.Context) { got = c.ClientIP() })
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRouterRejectsInvalidProxies(t *testing.T) {
+	if _, err := newRouter([]string{"not-an-ip"}); err == nil {
+		t.Error("newRouter() error = nil, want an error for an invalid proxy")
+	}
+}
+
+func TestAuthRoutesHitStricterLimitFirst(t *testing.T) {
+	s := newTestServer(t, map[string]string{
+		"RATE_LIMIT_REQUESTS":       "5",
+		"RATE_LIMIT_WRITE_REQUESTS": "5",
+		"AUTH_RATE_LIMIT_REQUESTS":  "2",
+	})
+
+	// Invalid logins are rejected before any query, and still count against both limits
+	for i := 1; i <= 2; i++ {
+		w := serve(s, http.MethodPost, "/api/v1/auth/login", "{}")
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("login %d status = %d, want 400", i, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("login %d X-RateLimit-Limit = %q, want the auth limit 2", i, got)
+		}
+	}
+	if w := serve(s, http.MethodPost, "/api/v1/auth/register", "{}"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third auth request status = %d, want 429", w.Code)
+	}
+
+	// The global limit still has room for other routes
+	w := serve(s, http.MethodGet, "/version", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("non-auth request status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "5" {
+		t.Errorf("non-auth X-RateLimit-Limit = %q, want the global limit 5", got)
+	}
+}
+
+func TestCacheHeadersByRoute(t *testing.T) {
+	s := newTestServer(t, map[string]string{"PRODUCT_CACHE_MAX_AGE": "120"})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{"rejected product list", http.MethodGet, "/api/v1/products?sort=bogus", "no-store"},
+		{"rejected product lookup", http.MethodGet, "/api/v1/products/not-a-uuid", "no-store"},
+		{"auth", http.MethodPost, "/api/v1/auth/login", "no-store"},
+		{"user", http.MethodGet, "/api/v1/me", "no-store"},
+		{"admin", http.MethodGet, "/api/v1/admin/products", "no-store"},
+		{"uncached public route", http.MethodGet, "/version", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(s, tt.method, tt.path, "")
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q (status %d)", got, tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRoutesWaitForStartup(t *testing.T) {
+	s := newStartingTestServer(t, nil)
+
+	if w := serve(s, http.MethodGet, "/health", ""); w.Code != http.StatusOK {
+		t.Errorf("GET /health while starting = %d, want %d", w.Code, http.StatusOK)
+	}
+	w := serve(s, http.MethodGet, "/health/ready", "")
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), `"status":"starting"`) {
+		t.Errorf("GET /health/ready while starting = %d %s, want 503 starting", w.Code, w.Body.String())
+	}
+	for _, path := range []string{"/version", "/api/v1/products", "/api/v1/cart"} {
+		if w := serve(s, http.MethodGet, path, ""); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("GET %s while starting = %d, want %d", path, w.Code, http.StatusServiceUnavailable)
+		}
+	}
+
+	s.health.MarkReady()
+	if w := serve(s, http.MethodGet, "/version", ""); w.Code != http.StatusOK {
+		t.Errorf("GET /version once started = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := serve(s, http.MethodGet, "/api/v1/cart", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/v1/cart once started = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}